@@ -4,83 +4,169 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 	"github.com/nianticlabs/venator/internal/signal"
-	"google.golang.org/api/iterator"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+	"google.golang.org/api/googleapi"
 )
 
+var logger = logrus.StandardLogger().WithField("pkg", "connector/bigquery")
+
+// defaultBatchSize follows the recommended maximum number of rows per insertAll request.
+const defaultBatchSize = 500
+
 type Client struct {
-	client   *bigquery.Client
-	inserter *bigquery.Inserter
+	client    *bigquery.Client
+	inserter  *bigquery.Inserter
+	writer    *storageWriter
+	schema    bigquery.Schema
+	batchSize int
+
+	dryRunBudget     int64
+	rejectOverBudget bool
 }
 
 func New(ctx context.Context, config Config) (*Client, error) {
-	var inserter *bigquery.Inserter
 	client, err := bigquery.NewClient(ctx, config.ProjectID)
 	if err != nil {
 		return nil, err
 	}
+	client.Location = config.Location
+	c := &Client{
+		client:           client,
+		batchSize:        config.BatchSize,
+		dryRunBudget:     config.DryRunBudget,
+		rejectOverBudget: config.RejectOverBudget,
+	}
+	if c.batchSize <= 0 {
+		c.batchSize = defaultBatchSize
+	}
+
 	if config.DatasetID != "" && config.TableID != "" {
-		// Check if the dataset and table exist
-		if _, err := client.Dataset(config.DatasetID).Metadata(ctx); err != nil {
+		meta, err := ensureTable(ctx, client, config)
+		if err != nil {
+			client.Close()
 			return nil, err
 		}
-		if _, err := client.Dataset(config.DatasetID).Table(config.TableID).Metadata(ctx); err != nil {
-			return nil, err
+		c.schema = meta.Schema
+
+		table := client.Dataset(config.DatasetID).Table(config.TableID)
+		if config.StorageWriteAPI {
+			c.writer, err = newStorageWriter(ctx, config.ProjectID, config.DatasetID, config.TableID, meta.Schema)
+			if err != nil {
+				client.Close()
+				return nil, fmt.Errorf("failed to open Storage Write API stream: %w", err)
+			}
+		} else {
+			c.inserter = table.Inserter()
 		}
-		inserter = client.Dataset(config.DatasetID).Table(config.TableID).Inserter()
 	}
 
-	return &Client{
-		client:   client,
-		inserter: inserter,
-	}, nil
+	return c, nil
 }
 
-func (c *Client) Query(ctx context.Context, cfg *config.RuleConfig) ([]map[string]string, error) {
-	query := c.client.Query(cfg.Query)
-	it, err := query.Read(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var results []map[string]string
-	for {
-		var row map[string]bigquery.Value
-		err := it.Next(&row)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-		if err != nil {
+// ensureTable returns the metadata of the destination table. If CreateTable is set, a missing
+// dataset or table is created instead of failing.
+func ensureTable(ctx context.Context, client *bigquery.Client, config Config) (*bigquery.TableMetadata, error) {
+	dataset := client.Dataset(config.DatasetID)
+	if _, err := dataset.Metadata(ctx); err != nil {
+		if !config.CreateTable || !isNotFound(err) {
 			return nil, err
 		}
-		res := make(map[string]string)
-		for k, v := range row {
-			res[k] = fmt.Sprintf("%v", v)
+		if err := dataset.Create(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to create dataset %s: %w", config.DatasetID, err)
 		}
-		results = append(results, res)
+		logger.Infof("created dataset %s", config.DatasetID)
+	}
+
+	table := dataset.Table(config.TableID)
+	meta, err := table.Metadata(ctx)
+	if err == nil {
+		return meta, nil
+	}
+	if !config.CreateTable || !isNotFound(err) {
+		return nil, err
+	}
+
+	meta = &bigquery.TableMetadata{
+		Schema: SignalSchema(),
+		TimePartitioning: &bigquery.TimePartitioning{
+			Type:  bigquery.DayPartitioningType,
+			Field: "timestamp",
+		},
+		Clustering: &bigquery.Clustering{
+			Fields: []string{"rule_id", "confidence"},
+		},
 	}
+	if err := table.Create(ctx, meta); err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", config.TableID, err)
+	}
+	logger.Infof("created table %s.%s", config.DatasetID, config.TableID)
+	return meta, nil
+}
 
-	return results, nil
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
 }
 
-func (c *Client) Publish(ctx context.Context, results []map[string]string, cfg *config.RuleConfig) error {
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
 	if len(results) == 0 {
 		return nil
 	}
+	if c.inserter == nil && c.writer == nil {
+		return fmt.Errorf("no destination table configured")
+	}
 
+	rows := make([]*row, 0, len(results))
+	dropped := make(map[string]struct{})
 	for _, r := range results {
 		output, err := signal.BuildOutput(r, cfg)
 		if err != nil {
 			return err
 		}
-
-		if err := c.inserter.Put(ctx, output); err != nil {
+		uid, err := signal.FindingUID(r, cfg)
+		if err != nil {
 			return err
 		}
+		row, droppedFields, err := buildRow(output, uid, c.schema, c.writer != nil)
+		if err != nil {
+			return fmt.Errorf("failed to build row: %w", err)
+		}
+		for _, field := range droppedFields {
+			dropped[field] = struct{}{}
+		}
+		rows = append(rows, row)
+	}
+	if len(dropped) > 0 {
+		fields := maps.Keys(dropped)
+		sort.Strings(fields)
+		logger.Warnf("rule %s: fields without a table column were not written: %v", cfg.Name, fields)
 	}
 
+	for start := 0; start < len(rows); start += c.batchSize {
+		batch := rows[start:min(start+c.batchSize, len(rows))]
+		if err := c.write(ctx, batch); err != nil {
+			return fmt.Errorf("failed to write rows %d-%d: %w", start, start+len(batch)-1, err)
+		}
+	}
+	logger.Infof("wrote %d row(s) for rule %s", len(rows), cfg.Name)
 	return nil
 }
+
+func (c *Client) write(ctx context.Context, rows []*row) error {
+	if c.writer != nil {
+		return c.writer.append(ctx, rows)
+	}
+	savers := make([]bigquery.ValueSaver, len(rows))
+	for i, r := range rows {
+		savers[i] = r
+	}
+	return c.inserter.Put(ctx, savers)
+}
@@ -0,0 +1,151 @@
+package bigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var testRule = &config.RuleConfig{
+	Name:       "test-rule",
+	UID:        "test-uid",
+	Confidence: config.ConfidenceHigh,
+	TTPs:       []config.TTP{{Framework: "MITRE", Tactic: "Persistence", Name: "Valid Accounts", ID: "T1078"}},
+	Output: config.Output{
+		Format: config.OutputFormatSignal,
+		Fields: []config.OutputField{
+			{Field: "Timestamp", Source: "timestamp"},
+			{Field: "ActorUserName", Source: "user"},
+			{Field: "RuleSpecificData", Source: "details"},
+		},
+	},
+}
+
+var testResult = result.Row{
+	"timestamp": "2024-03-01T12:00:00Z",
+	"user":      "alice",
+	"details":   `{"attempts": 3}`,
+}
+
+func TestSignalSchema(t *testing.T) {
+	schema := SignalSchema()
+	types := make(map[string]bigquery.FieldType)
+	for _, field := range schema {
+		types[field.Name] = field.Type
+	}
+
+	expected := map[string]bigquery.FieldType{
+		"finding_uid":        bigquery.StringFieldType,
+		"timestamp":          bigquery.TimestampFieldType,
+		"rule_id":            bigquery.StringFieldType,
+		"rule_name":          bigquery.StringFieldType,
+		"confidenceid":       bigquery.IntegerFieldType,
+		"confidence":         bigquery.StringFieldType,
+		"severityid":         bigquery.IntegerFieldType,
+		"severity":           bigquery.StringFieldType,
+		"ttps":               bigquery.JSONFieldType,
+		"actor":              bigquery.RecordFieldType,
+		"resource":           bigquery.RecordFieldType,
+		"src_endpoint":       bigquery.RecordFieldType,
+		"dst_endpoint":       bigquery.RecordFieldType,
+		"device":             bigquery.RecordFieldType,
+		"process":            bigquery.RecordFieldType,
+		"message":            bigquery.StringFieldType,
+		"metadata":           bigquery.RecordFieldType,
+		"rule_specific_data": bigquery.JSONFieldType,
+		"extensions":         bigquery.JSONFieldType,
+	}
+	if diff := cmp.Diff(expected, types); diff != "" {
+		t.Errorf("unexpected schema (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildRow(t *testing.T) {
+	output, err := signal.BuildOutput(testResult, testRule)
+	if err != nil {
+		t.Fatalf("BuildOutput() unexpected error: %v", err)
+	}
+
+	uid, err := signal.FindingUID(testResult, testRule)
+	if err != nil {
+		t.Fatalf("FindingUID() unexpected error: %v", err)
+	}
+	r, dropped, err := buildRow(output, uid, SignalSchema(), false)
+	if err != nil {
+		t.Fatalf("buildRow() unexpected error: %v", err)
+	}
+	if len(dropped) != 0 {
+		t.Errorf("expected no dropped fields, got %v", dropped)
+	}
+	if got := r.values["timestamp"]; got != "2024-03-01T12:00:00Z" {
+		t.Errorf("unexpected timestamp %v", got)
+	}
+	if got := r.values["rule_specific_data"]; got != `{"attempts":"3"}` {
+		t.Errorf("unexpected rule_specific_data %v", got)
+	}
+	actor := r.values["actor"].(map[string]bigquery.Value)
+	if got := actor["user"].(map[string]bigquery.Value)["name"]; got != "alice" {
+		t.Errorf("unexpected actor.user.name %v", got)
+	}
+
+	// Insert IDs are finding UIDs, so retried findings are deduplicated.
+	if r.insertID != uid {
+		t.Errorf("expected insert ID %q, got %q", uid, r.insertID)
+	}
+}
+
+func TestBuildRowDropsUnknownFields(t *testing.T) {
+	schema := bigquery.Schema{{Name: "user", Type: bigquery.StringFieldType}}
+	r, dropped, err := buildRow(result.Row{"user": "alice", "host": "web-1"}, "", schema, false)
+	if err != nil {
+		t.Fatalf("buildRow() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]bigquery.Value{"user": "alice"}, r.values); diff != "" {
+		t.Errorf("unexpected values (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"host"}, dropped); diff != "" {
+		t.Errorf("unexpected dropped fields (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncodeRow(t *testing.T) {
+	output, err := signal.BuildOutput(testResult, testRule)
+	if err != nil {
+		t.Fatalf("BuildOutput() unexpected error: %v", err)
+	}
+	r, _, err := buildRow(output, "", SignalSchema(), true)
+	if err != nil {
+		t.Fatalf("buildRow() unexpected error: %v", err)
+	}
+
+	descriptor, err := rowDescriptor(SignalSchema())
+	if err != nil {
+		t.Fatalf("rowDescriptor() unexpected error: %v", err)
+	}
+	b, err := encodeRow(descriptor, r)
+	if err != nil {
+		t.Fatalf("encodeRow() unexpected error: %v", err)
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(b, msg); err != nil {
+		t.Fatalf("failed to decode row: %v", err)
+	}
+	fields := descriptor.Fields()
+	if got := msg.Get(fields.ByName("timestamp")).Int(); got != 1709294400000000 {
+		t.Errorf("unexpected timestamp %d", got)
+	}
+	if got := msg.Get(fields.ByName("confidenceid")).Int(); got != int64(signal.ConfidenceHigh) {
+		t.Errorf("unexpected confidenceid %d", got)
+	}
+	if got := msg.Get(fields.ByName("ttps")).String(); got != `[{"framework":"MITRE","id":"T1078","name":"Valid Accounts","tactic":"Persistence"}]` {
+		t.Errorf("unexpected ttps %s", got)
+	}
+}
@@ -4,4 +4,20 @@ type Config struct {
 	ProjectID string
 	DatasetID string
 	TableID   string
+
+	// CreateTable creates the dataset and table if they don't exist. The table uses the signal
+	// schema, partitioned by day on timestamp and clustered by rule_id and confidence.
+	CreateTable bool
+	// StorageWriteAPI streams rows through the Storage Write API default stream instead of
+	// the legacy insertAll API. It has higher throughput but no insert ID deduplication.
+	StorageWriteAPI bool
+	// BatchSize is the maximum number of rows sent in one request. Defaults to 500.
+	BatchSize int
+
+	// Location is where query jobs run, e.g. US or europe-west1.
+	Location string
+	// DryRunBudget is the number of bytes a query may process. If set, every query is dry-run
+	// first and exceeding the budget is logged, or rejected when RejectOverBudget is set.
+	DryRunBudget     int64
+	RejectOverBudget bool
 }
@@ -0,0 +1,149 @@
+package bigquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// convertRow turns a query result row into a result row based on the result schema. Integers, floats
+// and booleans keep their type, other scalars are formatted so signal.BuildSignal and exclusions can
+// parse them (e.g. RFC3339 timestamps), non-repeated records are flattened into dotted keys, arrays
+// become lists and JSON columns are decoded.
+func convertRow(values []bigquery.Value, schema bigquery.Schema) (result.Row, error) {
+	res := make(result.Row)
+	if err := flattenRecord("", values, schema, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func flattenRecord(prefix string, values []bigquery.Value, schema bigquery.Schema, res result.Row) error {
+	if len(values) != len(schema) {
+		return fmt.Errorf("row has %d values but the schema has %d fields", len(values), len(schema))
+	}
+	for i, field := range schema {
+		key := prefix + field.Name
+		v := values[i]
+
+		switch {
+		case v == nil:
+			res[key] = nil
+		case field.Type == bigquery.RecordFieldType && !field.Repeated:
+			record, ok := v.([]bigquery.Value)
+			if !ok {
+				return fmt.Errorf("field %s: expected a record, got %T", key, v)
+			}
+			if err := flattenRecord(key+".", record, field.Schema, res); err != nil {
+				return err
+			}
+		default:
+			value, err := jsonValue(v, field)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", key, err)
+			}
+			res[key] = value
+		}
+	}
+	return nil
+}
+
+// jsonValue converts a value into its JSON-compatible form.
+func jsonValue(v bigquery.Value, field *bigquery.FieldSchema) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	if field.Repeated {
+		items, ok := v.([]bigquery.Value)
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %T", v)
+		}
+		elem := *field
+		elem.Repeated = false
+		out := make([]any, 0, len(items))
+		for _, item := range items {
+			value, err := jsonValue(item, &elem)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		return out, nil
+	}
+
+	switch field.Type {
+	case bigquery.RecordFieldType:
+		record, ok := v.([]bigquery.Value)
+		if !ok || len(record) != len(field.Schema) {
+			return nil, fmt.Errorf("expected a record, got %T", v)
+		}
+		out := make(map[string]any, len(record))
+		for i, child := range field.Schema {
+			value, err := jsonValue(record[i], child)
+			if err != nil {
+				return nil, err
+			}
+			out[child.Name] = value
+		}
+		return out, nil
+	case bigquery.JSONFieldType:
+		// JSON columns are decoded instead of kept as an escaped string.
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		decoder := json.NewDecoder(strings.NewReader(s))
+		decoder.UseNumber()
+		var decoded any
+		if err := decoder.Decode(&decoded); err != nil {
+			return s, nil
+		}
+		return result.Normalize(decoded), nil
+	case bigquery.IntegerFieldType, bigquery.FloatFieldType, bigquery.BooleanFieldType:
+		return v, nil
+	default:
+		return scalarString(v, field.Type)
+	}
+}
+
+func scalarString(v bigquery.Value, fieldType bigquery.FieldType) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(val), nil
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano), nil
+	case civil.Date:
+		return val.String(), nil
+	case civil.Time:
+		return val.String(), nil
+	case civil.DateTime:
+		return val.String(), nil
+	case *big.Rat:
+		if fieldType == bigquery.BigNumericFieldType {
+			return bigquery.BigNumericString(val), nil
+		}
+		return bigquery.NumericString(val), nil
+	case fmt.Stringer:
+		return val.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
@@ -0,0 +1,81 @@
+package bigquery
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestConvertRow(t *testing.T) {
+	schema := bigquery.Schema{
+		{Name: "timestamp", Type: bigquery.TimestampFieldType},
+		{Name: "day", Type: bigquery.DateFieldType},
+		{Name: "count", Type: bigquery.IntegerFieldType},
+		{Name: "score", Type: bigquery.FloatFieldType},
+		{Name: "amount", Type: bigquery.NumericFieldType},
+		{Name: "admin", Type: bigquery.BooleanFieldType},
+		{Name: "comment", Type: bigquery.StringFieldType},
+		{Name: "signals", Type: bigquery.StringFieldType, Repeated: true},
+		{Name: "details", Type: bigquery.JSONFieldType},
+		{Name: "actor", Type: bigquery.RecordFieldType, Schema: bigquery.Schema{
+			{Name: "user", Type: bigquery.RecordFieldType, Schema: bigquery.Schema{
+				{Name: "name", Type: bigquery.StringFieldType},
+				{Name: "uid", Type: bigquery.StringFieldType},
+			}},
+		}},
+		{Name: "logins", Type: bigquery.RecordFieldType, Repeated: true, Schema: bigquery.Schema{
+			{Name: "ip", Type: bigquery.StringFieldType},
+			{Name: "at", Type: bigquery.TimestampFieldType},
+		}},
+	}
+	loginTime := time.Date(2024, 3, 1, 11, 30, 0, 0, time.UTC)
+	row := []bigquery.Value{
+		time.Date(2024, 3, 1, 12, 0, 0, 500000000, time.UTC),
+		civil.Date{Year: 2024, Month: 3, Day: 1},
+		int64(42),
+		float64(1000000),
+		big.NewRat(25, 2),
+		true,
+		nil,
+		[]bigquery.Value{"rule-a", "rule-b"},
+		`{"attempts": 3}`,
+		[]bigquery.Value{[]bigquery.Value{"alice", nil}},
+		[]bigquery.Value{[]bigquery.Value{"10.0.0.1", loginTime}},
+	}
+
+	expected := result.Row{
+		"timestamp":       "2024-03-01T12:00:00.5Z",
+		"day":             "2024-03-01",
+		"count":           int64(42),
+		"score":           float64(1000000),
+		"amount":          "12.500000000",
+		"admin":           true,
+		"comment":         nil,
+		"signals":         []any{"rule-a", "rule-b"},
+		"details":         map[string]any{"attempts": int64(3)},
+		"actor.user.name": "alice",
+		"actor.user.uid":  nil,
+		"logins":          []any{map[string]any{"at": "2024-03-01T11:30:00Z", "ip": "10.0.0.1"}},
+	}
+
+	res, err := convertRow(row, schema)
+	if err != nil {
+		t.Fatalf("convertRow() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertRowSchemaMismatch(t *testing.T) {
+	schema := bigquery.Schema{{Name: "a", Type: bigquery.StringFieldType}}
+	if _, err := convertRow([]bigquery.Value{"x", "y"}, schema); err == nil {
+		t.Errorf("expected an error for a row not matching the schema")
+	}
+}
@@ -0,0 +1,170 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// Named parameters bound to every query that references them.
+const (
+	paramWindowStart = "window_start"
+	paramWindowEnd   = "window_end"
+	paramRuleUID     = "rule_uid"
+)
+
+// paramPatterns match references to each named parameter, ignoring longer names sharing its prefix.
+var paramPatterns = map[string]*regexp.Regexp{
+	paramWindowStart: regexp.MustCompile(`@` + paramWindowStart + `\b`),
+	paramWindowEnd:   regexp.MustCompile(`@` + paramWindowEnd + `\b`),
+	paramRuleUID:     regexp.MustCompile(`@` + paramRuleUID + `\b`),
+}
+
+// maxLabelLength is the maximum length of BigQuery label keys and values.
+const maxLabelLength = 63
+
+func (c *Client) Query(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error) {
+	query, err := c.buildQuery(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dryRunBudget > 0 {
+		if err := c.dryRun(ctx, query, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	it, err := query.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []result.Row
+	for {
+		var row []bigquery.Value
+		err := it.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The iterator only knows the result schema once the first page was fetched.
+		res, err := convertRow(row, it.Schema)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+func (c *Client) buildQuery(cfg *config.RuleConfig) (*bigquery.Query, error) {
+	params, err := queryParameters(cfg, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	query := c.client.Query(cfg.Query)
+	query.Parameters = params
+	query.Labels = jobLabels(cfg)
+	if cfg.BigQuery != nil {
+		query.MaxBytesBilled = cfg.BigQuery.MaximumBytesBilled
+	}
+	return query, nil
+}
+
+// dryRun estimates the bytes processed by query and compares them against the budget.
+func (c *Client) dryRun(ctx context.Context, query *bigquery.Query, cfg *config.RuleConfig) error {
+	dry := *query
+	dry.DryRun = true
+	job, err := dry.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("dry run failed: %w", err)
+	}
+	status := job.LastStatus()
+	if status == nil || status.Statistics == nil {
+		return fmt.Errorf("dry run returned no statistics")
+	}
+
+	bytes := status.Statistics.TotalBytesProcessed
+	logger.Infof("rule %s: query will process %d bytes", cfg.Name, bytes)
+	return checkBudget(bytes, c.dryRunBudget, c.rejectOverBudget)
+}
+
+func checkBudget(bytes, budget int64, reject bool) error {
+	if budget <= 0 || bytes <= budget {
+		return nil
+	}
+	if reject {
+		return fmt.Errorf("query would process %d bytes, exceeding the budget of %d bytes", bytes, budget)
+	}
+	logger.Warnf("query will process %d bytes, exceeding the budget of %d bytes", bytes, budget)
+	return nil
+}
+
+// queryParameters returns the named parameters referenced by the rule's query. Parameters are only
+// bound when used, since not every rule covers a time window.
+func queryParameters(cfg *config.RuleConfig, end time.Time) ([]bigquery.QueryParameter, error) {
+	var params []bigquery.QueryParameter
+	if references(cfg.Query, paramWindowStart) || references(cfg.Query, paramWindowEnd) {
+		if cfg.BigQuery == nil || cfg.BigQuery.Window <= 0 {
+			return nil, fmt.Errorf("query references @%s or @%s but bigquery.window is not set", paramWindowStart, paramWindowEnd)
+		}
+		params = append(params,
+			bigquery.QueryParameter{Name: paramWindowStart, Value: end.Add(-cfg.BigQuery.Window)},
+			bigquery.QueryParameter{Name: paramWindowEnd, Value: end},
+		)
+	}
+	if references(cfg.Query, paramRuleUID) {
+		params = append(params, bigquery.QueryParameter{Name: paramRuleUID, Value: cfg.UID})
+	}
+	return params, nil
+}
+
+// references reports whether query uses the named parameter.
+func references(query, name string) bool {
+	return paramPatterns[name].MatchString(query)
+}
+
+// jobLabels returns the labels attached to query jobs, so costs can be attributed to rules.
+func jobLabels(cfg *config.RuleConfig) map[string]string {
+	labels := map[string]string{
+		"venator_rule":     labelValue(cfg.Name),
+		"venator_rule_uid": labelValue(cfg.UID),
+	}
+	if cfg.BigQuery != nil {
+		for k, v := range cfg.BigQuery.Labels {
+			labels[labelValue(k)] = labelValue(v)
+		}
+	}
+	return labels
+}
+
+// labelValue converts s to the label character set: lowercase letters, digits, underscores and dashes.
+func labelValue(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	out := b.String()
+	if len(out) > maxLabelLength {
+		out = out[:maxLabelLength]
+	}
+	return out
+}
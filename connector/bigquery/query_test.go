@@ -0,0 +1,101 @@
+package bigquery
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+)
+
+func TestQueryParameters(t *testing.T) {
+	end := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	window := &config.BigQuery{Window: 2 * time.Hour}
+
+	tests := []struct {
+		name       string
+		cfg        *config.RuleConfig
+		expected   []bigquery.QueryParameter
+		errMessage string
+	}{
+		{
+			name: "window and rule UID",
+			cfg: &config.RuleConfig{
+				UID:      "test-uid",
+				BigQuery: window,
+				Query:    "SELECT * FROM logs WHERE ts >= @window_start AND ts < @window_end AND rule != @rule_uid",
+			},
+			expected: []bigquery.QueryParameter{
+				{Name: "window_start", Value: end.Add(-2 * time.Hour)},
+				{Name: "window_end", Value: end},
+				{Name: "rule_uid", Value: "test-uid"},
+			},
+		},
+		{
+			name: "unreferenced parameters are not bound",
+			cfg: &config.RuleConfig{
+				UID:      "test-uid",
+				BigQuery: window,
+				Query:    "SELECT * FROM logs WHERE rule = @rule_uid_v2",
+			},
+		},
+		{
+			name:       "window without duration",
+			cfg:        &config.RuleConfig{Query: "SELECT * FROM logs WHERE ts >= @window_start"},
+			errMessage: "bigquery.window is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := queryParameters(tt.cfg, end)
+			if tt.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+					t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("queryParameters() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, params); diff != "" {
+				t.Errorf("unexpected parameters (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJobLabels(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Name: "Stage-2 Signal",
+		UID:  "F1E2D3C4-b5a6",
+		BigQuery: &config.BigQuery{
+			Labels: map[string]string{"team": "SecOps", "cost_center": strings.Repeat("x", 70)},
+		},
+	}
+
+	expected := map[string]string{
+		"venator_rule":     "stage-2_signal",
+		"venator_rule_uid": "f1e2d3c4-b5a6",
+		"team":             "secops",
+		"cost_center":      strings.Repeat("x", maxLabelLength),
+	}
+	if diff := cmp.Diff(expected, jobLabels(cfg)); diff != "" {
+		t.Errorf("unexpected labels (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	if err := checkBudget(2048, 1024, false); err != nil {
+		t.Errorf("expected exceeding the budget to only be logged, got %v", err)
+	}
+	if err := checkBudget(2048, 1024, true); err == nil {
+		t.Errorf("expected exceeding the budget to be rejected")
+	}
+	if err := checkBudget(512, 1024, true); err != nil {
+		t.Errorf("unexpected error within budget: %v", err)
+	}
+}
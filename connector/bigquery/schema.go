@@ -0,0 +1,188 @@
+package bigquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SignalSchema returns the table schema for signal outputs. It is derived from the JSON tags of
+// signal.Signal, so rows written by Publish match what other publishers emit. Maps and lists of
+// maps have no fixed keys and are stored as JSON columns.
+func SignalSchema() bigquery.Schema {
+	return structSchema(reflect.TypeOf(signal.Signal{}))
+}
+
+func structSchema(t reflect.Type) bigquery.Schema {
+	var schema bigquery.Schema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := fieldSchema(f.Type)
+		field.Name = name
+		schema = append(schema, field)
+	}
+	return schema
+}
+
+func fieldSchema(t reflect.Type) *bigquery.FieldSchema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &bigquery.FieldSchema{Type: bigquery.TimestampFieldType}
+	case t.Kind() == reflect.Struct:
+		return &bigquery.FieldSchema{Type: bigquery.RecordFieldType, Schema: structSchema(t)}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Map && t.Elem().Kind() != reflect.Uint8:
+		field := fieldSchema(t.Elem())
+		field.Repeated = true
+		return field
+	case t.Kind() == reflect.String:
+		return &bigquery.FieldSchema{Type: bigquery.StringFieldType}
+	case t.Kind() == reflect.Bool:
+		return &bigquery.FieldSchema{Type: bigquery.BooleanFieldType}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &bigquery.FieldSchema{Type: bigquery.IntegerFieldType}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &bigquery.FieldSchema{Type: bigquery.FloatFieldType}
+	default:
+		return &bigquery.FieldSchema{Type: bigquery.JSONFieldType}
+	}
+}
+
+// row is a publisher output converted to column values of the destination table.
+type row struct {
+	values   map[string]bigquery.Value
+	insertID string
+}
+
+// Save implements bigquery.ValueSaver.
+func (r *row) Save() (map[string]bigquery.Value, string, error) {
+	return r.values, r.insertID, nil
+}
+
+// buildRow converts an output built by signal.BuildOutput into values matching schema. Fields that
+// have no column in the table are returned as dropped. With storage set, values are converted to
+// what the Storage Write API protobuf encoding expects instead of the insertAll JSON encoding. The
+// insert ID lets BigQuery drop rows retried within its deduplication window.
+func buildRow(output any, insertID string, schema bigquery.Schema, storage bool) (*row, []string, error) {
+	outputJSON, err := json.Marshal(output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var obj map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(outputJSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, nil, err
+	}
+
+	values, err := convertRecord(obj, schema, storage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var dropped []string
+	for k := range obj {
+		if !hasField(schema, k) {
+			dropped = append(dropped, k)
+		}
+	}
+
+	return &row{values: values, insertID: insertID}, dropped, nil
+}
+
+func convertRecord(obj map[string]any, schema bigquery.Schema, storage bool) (map[string]bigquery.Value, error) {
+	values := make(map[string]bigquery.Value)
+	for _, field := range schema {
+		v, ok := obj[field.Name]
+		if !ok || v == nil {
+			continue
+		}
+		value, err := convertValue(v, field, storage)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		values[field.Name] = value
+	}
+	return values, nil
+}
+
+func convertValue(v any, field *bigquery.FieldSchema, storage bool) (bigquery.Value, error) {
+	if field.Repeated {
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected a list, got %T", v)
+		}
+		elem := *field
+		elem.Repeated = false
+		values := make([]bigquery.Value, 0, len(items))
+		for _, item := range items {
+			value, err := convertValue(item, &elem, storage)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	switch field.Type {
+	case bigquery.RecordFieldType:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %T", v)
+		}
+		return convertRecord(obj, field.Schema, storage)
+	case bigquery.JSONFieldType:
+		valueJSON, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(valueJSON), nil
+	case bigquery.TimestampFieldType:
+		if !storage {
+			return v, nil
+		}
+		// The Storage Write API encodes timestamps as microseconds since the epoch.
+		ts, err := time.Parse(time.RFC3339Nano, fmt.Sprint(v))
+		if err != nil {
+			return nil, err
+		}
+		return ts.UnixMicro(), nil
+	case bigquery.BooleanFieldType:
+		if s, ok := v.(string); ok && storage {
+			return strconv.ParseBool(s)
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func hasField(schema bigquery.Schema, name string) bool {
+	for _, field := range schema {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package bigquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter"
+	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// storageWriter appends rows to the default stream of a table through the Storage Write API.
+// Rows are encoded as dynamic protobuf messages built from the table schema.
+type storageWriter struct {
+	client     *managedwriter.Client
+	stream     *managedwriter.ManagedStream
+	descriptor protoreflect.MessageDescriptor
+}
+
+func newStorageWriter(ctx context.Context, projectID, datasetID, tableID string, schema bigquery.Schema) (*storageWriter, error) {
+	messageDescriptor, err := rowDescriptor(schema)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := adapt.NormalizeDescriptor(messageDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize row descriptor: %w", err)
+	}
+
+	client, err := managedwriter.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.NewManagedStream(ctx,
+		managedwriter.WithDestinationTable(managedwriter.TableParentFromParts(projectID, datasetID, tableID)),
+		managedwriter.WithType(managedwriter.DefaultStream),
+		managedwriter.WithSchemaDescriptor(normalized),
+	)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &storageWriter{
+		client:     client,
+		stream:     stream,
+		descriptor: messageDescriptor,
+	}, nil
+}
+
+func rowDescriptor(schema bigquery.Schema) (protoreflect.MessageDescriptor, error) {
+	storageSchema, err := adapt.BQSchemaToStorageTableSchema(jsonAsString(schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert table schema: %w", err)
+	}
+	descriptor, err := adapt.StorageSchemaToProto2Descriptor(storageSchema, "root")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build row descriptor: %w", err)
+	}
+	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("row descriptor is not a message descriptor")
+	}
+	return messageDescriptor, nil
+}
+
+// jsonAsString returns a copy of schema with JSON columns declared as STRING. The adapt package
+// can't convert JSON columns, but the Storage Write API accepts them as proto strings.
+func jsonAsString(schema bigquery.Schema) bigquery.Schema {
+	out := make(bigquery.Schema, len(schema))
+	for i, field := range schema {
+		f := *field
+		if f.Type == bigquery.JSONFieldType {
+			f.Type = bigquery.StringFieldType
+		}
+		f.Schema = jsonAsString(f.Schema)
+		out[i] = &f
+	}
+	return out
+}
+
+// encodeRow serializes row values, converted for storage, into a protobuf message of the table schema.
+func encodeRow(descriptor protoreflect.MessageDescriptor, r *row) ([]byte, error) {
+	rowJSON, err := json.Marshal(r.values)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(descriptor)
+	if err := protojson.Unmarshal(rowJSON, msg); err != nil {
+		return nil, fmt.Errorf("failed to encode row: %w", err)
+	}
+	return proto.Marshal(msg)
+}
+
+// append writes rows in a single AppendRows request and waits for it to be acknowledged.
+func (w *storageWriter) append(ctx context.Context, rows []*row) error {
+	data := make([][]byte, 0, len(rows))
+	for _, r := range rows {
+		b, err := encodeRow(w.descriptor, r)
+		if err != nil {
+			return err
+		}
+		data = append(data, b)
+	}
+
+	result, err := w.stream.AppendRows(ctx, data)
+	if err != nil {
+		return err
+	}
+	_, err = result.GetResult(ctx)
+	return err
+}
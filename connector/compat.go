@@ -0,0 +1,57 @@
+package connector
+
+import (
+	"context"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// StringQueryRunner is the QueryRunner interface from before results were typed rows.
+type StringQueryRunner interface {
+	Query(ctx context.Context, ruleConfig *config.RuleConfig) ([]map[string]string, error)
+}
+
+// StringPublisher is the Publisher interface from before results were typed rows.
+type StringPublisher interface {
+	Publish(ctx context.Context, data []map[string]string, ruleConfig *config.RuleConfig) error
+}
+
+// FromStringQueryRunner adapts a query runner returning string maps. If it also implements
+// Acknowledger, so does the returned QueryRunner.
+func FromStringQueryRunner(qr StringQueryRunner) QueryRunner {
+	adapter := stringQueryRunner{qr}
+	if acker, ok := qr.(Acknowledger); ok {
+		return struct {
+			stringQueryRunner
+			Acknowledger
+		}{adapter, acker}
+	}
+	return adapter
+}
+
+// FromStringPublisher adapts a publisher consuming string maps. Typed values are flattened with
+// result.Row.Flatten before they are passed on.
+func FromStringPublisher(pub StringPublisher) Publisher {
+	return stringPublisher{pub}
+}
+
+type stringQueryRunner struct {
+	qr StringQueryRunner
+}
+
+func (a stringQueryRunner) Query(ctx context.Context, ruleConfig *config.RuleConfig) ([]result.Row, error) {
+	results, err := a.qr.Query(ctx, ruleConfig)
+	if err != nil {
+		return nil, err
+	}
+	return result.FromStringMaps(results), nil
+}
+
+type stringPublisher struct {
+	pub StringPublisher
+}
+
+func (a stringPublisher) Publish(ctx context.Context, data []result.Row, ruleConfig *config.RuleConfig) error {
+	return a.pub.Publish(ctx, result.Flatten(data), ruleConfig)
+}
@@ -0,0 +1,113 @@
+package connector_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/connector"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+type stringRunner struct {
+	results []map[string]string
+	err     error
+}
+
+func (r *stringRunner) Query(ctx context.Context, ruleConfig *config.RuleConfig) ([]map[string]string, error) {
+	return r.results, r.err
+}
+
+type ackingStringRunner struct {
+	stringRunner
+	acked, nacked int
+}
+
+func (r *ackingStringRunner) Ack(ctx context.Context) error {
+	r.acked++
+	return nil
+}
+
+func (r *ackingStringRunner) Nack(ctx context.Context) error {
+	r.nacked++
+	return errors.New("nack failed")
+}
+
+type stringPublisher struct {
+	published []map[string]string
+}
+
+func (p *stringPublisher) Publish(ctx context.Context, data []map[string]string, ruleConfig *config.RuleConfig) error {
+	p.published = append(p.published, data...)
+	return nil
+}
+
+func TestFromStringQueryRunner(t *testing.T) {
+	ctx := context.Background()
+	qr := connector.FromStringQueryRunner(&stringRunner{results: []map[string]string{{"user": "alice", "count": "3"}}})
+
+	got, err := qr.Query(ctx, &config.RuleConfig{})
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]result.Row{{"user": "alice", "count": "3"}}, got); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+	if _, ok := qr.(connector.Acknowledger); ok {
+		t.Errorf("expected a runner without Ack/Nack not to be an Acknowledger")
+	}
+
+	wantErr := errors.New("query failed")
+	qr = connector.FromStringQueryRunner(&stringRunner{err: wantErr})
+	if _, err := qr.Query(ctx, &config.RuleConfig{}); !errors.Is(err, wantErr) {
+		t.Errorf("expected the query error to be returned, got %v", err)
+	}
+}
+
+func TestFromStringQueryRunnerAcknowledger(t *testing.T) {
+	ctx := context.Background()
+	runner := &ackingStringRunner{stringRunner: stringRunner{results: []map[string]string{{"user": "alice"}}}}
+	qr := connector.FromStringQueryRunner(runner)
+
+	got, err := qr.Query(ctx, &config.RuleConfig{})
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]result.Row{{"user": "alice"}}, got); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+
+	acker, ok := qr.(connector.Acknowledger)
+	if !ok {
+		t.Fatalf("expected the adapter of an Acknowledger to be an Acknowledger")
+	}
+	if err := acker.Ack(ctx); err != nil {
+		t.Errorf("Ack() unexpected error: %v", err)
+	}
+	if err := acker.Nack(ctx); err == nil || err.Error() != "nack failed" {
+		t.Errorf("expected the Nack error to be returned, got %v", err)
+	}
+	if runner.acked != 1 || runner.nacked != 1 {
+		t.Errorf("expected one Ack and one Nack, got %d and %d", runner.acked, runner.nacked)
+	}
+}
+
+func TestFromStringPublisher(t *testing.T) {
+	pub := &stringPublisher{}
+	rows := []result.Row{{
+		"user":  "alice",
+		"count": int64(3),
+		"actor": map[string]any{"ip": "10.0.0.1"},
+	}}
+
+	if err := connector.FromStringPublisher(pub).Publish(context.Background(), rows, &config.RuleConfig{}); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	expected := []map[string]string{{"user": "alice", "count": "3", "actor.ip": "10.0.0.1"}}
+	if diff := cmp.Diff(expected, pub.published); diff != "" {
+		t.Errorf("unexpected published data (-want +got):\n%s", diff)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 )
 
 type Connector interface {
@@ -11,9 +12,16 @@ type Connector interface {
 }
 
 type QueryRunner interface {
-	Query(ctx context.Context, ruleConfig *config.RuleConfig) ([]map[string]string, error)
+	Query(ctx context.Context, ruleConfig *config.RuleConfig) ([]result.Row, error)
 }
 
 type Publisher interface {
-	Publish(ctx context.Context, data []map[string]string, ruleConfig *config.RuleConfig) error
+	Publish(ctx context.Context, data []result.Row, ruleConfig *config.RuleConfig) error
+}
+
+// Acknowledger is implemented by query runners that read from a queue. Ack is called once all publishers
+// succeeded, so the results are only removed from the queue after they were handled; Nack otherwise.
+type Acknowledger interface {
+	Ack(ctx context.Context) error
+	Nack(ctx context.Context) error
 }
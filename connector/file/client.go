@@ -0,0 +1,275 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+type Client struct {
+	path           string
+	format         string
+	gzip           bool
+	maxSize        int64
+	rotateInterval time.Duration
+	writer         io.Writer
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+const (
+	FormatJSONL   = "jsonl"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+
+	rotatedTimeLayout = "20060102T150405Z"
+	// maxRotatedFiles bounds the files with the same timestamp.
+	maxRotatedFiles = 1000
+	filePerm        = 0o640
+)
+
+func New(ctx context.Context, config Config) (*Client, error) {
+	switch config.Format {
+	case FormatJSONL, FormatCSV:
+	case FormatParquet:
+		if config.Writer != nil {
+			return nil, fmt.Errorf("format '%s' is not supported for stream output", config.Format)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file format '%s'", config.Format)
+	}
+	if config.Writer == nil && config.Path == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+
+	return &Client{
+		path:           config.Path,
+		format:         config.Format,
+		gzip:           config.Gzip,
+		maxSize:        config.MaxSize,
+		rotateInterval: config.RotateInterval,
+		writer:         config.Writer,
+		now:            time.Now,
+	}, nil
+}
+
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var docs []any
+	for _, r := range results {
+		output, err := signal.BuildOutput(r, cfg)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, output)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.writer != nil {
+		return c.writeStream(docs)
+	}
+	if c.format == FormatParquet {
+		return c.writeParquetFile(docs)
+	}
+	return c.appendFile(docs)
+}
+
+func (c *Client) writeStream(docs []any) error {
+	if c.format == FormatJSONL {
+		return writeJSONL(c.writer, docs)
+	}
+
+	rows, columns, err := flattenDocuments(docs)
+	if err != nil {
+		return err
+	}
+	return writeCSV(c.writer, rows, columns, true)
+}
+
+// writeParquetFile writes each batch to its own timestamped file, since parquet files can't be appended to.
+func (c *Client) writeParquetFile(docs []any) error {
+	rows, columns, err := flattenDocuments(docs)
+	if err != nil {
+		return err
+	}
+
+	f, err := createRotated(c.path, c.now())
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	// The parquet writer closes the file once the footer is written.
+	if err := writeParquet(f, rows, columns, c.gzip); err != nil {
+		f.Close()
+		return err
+	}
+	return nil
+}
+
+// appendFile appends the documents to the active file, rotating it first if needed.
+// Gzip output is appended as a new gzip member, which standard readers decode as one stream.
+func (c *Client) appendFile(docs []any) (err error) {
+	var rows []map[string]string
+	var columns, header []string
+	if c.format == FormatCSV {
+		rows, columns, err = flattenDocuments(docs)
+		if err != nil {
+			return err
+		}
+	}
+
+	info, err := os.Stat(c.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to stat output file: %w", err)
+	}
+	if info != nil && info.Size() > 0 {
+		if c.format == FormatCSV {
+			if header, err = readCSVHeader(c.path, c.gzip); err != nil {
+				return err
+			}
+		}
+		// A CSV file can only take rows whose columns are all in its header.
+		if c.shouldRotate(info) || (c.format == FormatCSV && !isSubset(columns, header)) {
+			if err := rotateFile(c.path, info.ModTime()); err != nil {
+				return fmt.Errorf("failed to rotate output file: %w", err)
+			}
+			header = nil
+		}
+	}
+
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var w io.Writer = f
+	if c.gzip {
+		gz := gzip.NewWriter(f)
+		defer func() {
+			if closeErr := gz.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = gz
+	}
+
+	if c.format == FormatJSONL {
+		return writeJSONL(w, docs)
+	}
+	if header != nil {
+		return writeCSV(w, rows, header, false)
+	}
+	return writeCSV(w, rows, columns, true)
+}
+
+func (c *Client) shouldRotate(info os.FileInfo) bool {
+	if c.maxSize > 0 && info.Size() >= c.maxSize {
+		return true
+	}
+	if c.rotateInterval > 0 {
+		return !info.ModTime().Truncate(c.rotateInterval).Equal(c.now().Truncate(c.rotateInterval))
+	}
+	return false
+}
+
+// createRotated creates a file named after path and t, numbering it if the name is taken, e.g.
+// by another batch written in the same second.
+func createRotated(path string, t time.Time) (*os.File, error) {
+	for n := 0; n < maxRotatedFiles; n++ {
+		f, err := os.OpenFile(rotatedPath(path, t, n), os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePerm)
+		if !errors.Is(err, os.ErrExist) {
+			return f, err
+		}
+	}
+	return nil, fmt.Errorf("more than %d files for %s", maxRotatedFiles, rotatedPath(path, t, 0))
+}
+
+// rotateFile renames path after t, numbering it if an earlier rotated file has the same name.
+func rotateFile(path string, t time.Time) error {
+	for n := 0; n < maxRotatedFiles; n++ {
+		target := rotatedPath(path, t, n)
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return os.Rename(path, target)
+	}
+	return fmt.Errorf("more than %d files for %s", maxRotatedFiles, rotatedPath(path, t, 0))
+}
+
+// rotatedPath inserts a UTC timestamp, followed by n if it isn't 0, before the file extensions,
+// e.g. findings.jsonl.gz becomes findings-20240101T000000Z.jsonl.gz or
+// findings-20240101T000000Z-1.jsonl.gz.
+func rotatedPath(path string, t time.Time, n int) string {
+	dir, base := filepath.Split(path)
+	stamp := t.UTC().Format(rotatedTimeLayout)
+	if n > 0 {
+		stamp += "-" + strconv.Itoa(n)
+	}
+	if idx := strings.Index(base, "."); idx > 0 {
+		return filepath.Join(dir, base[:idx]+"-"+stamp+base[idx:])
+	}
+	return filepath.Join(dir, base+"-"+stamp)
+}
+
+func readCSVHeader(path string, gzipped bool) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip output file: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	header, err := csv.NewReader(r).Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	return header, nil
+}
+
+func isSubset(columns, header []string) bool {
+	known := make(map[string]struct{}, len(header))
+	for _, h := range header {
+		known[h] = struct{}{}
+	}
+	for _, col := range columns {
+		if _, ok := known[col]; !ok {
+			return false
+		}
+	}
+	return true
+}
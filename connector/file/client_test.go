@@ -0,0 +1,223 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/file"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var (
+	rawRuleConfig = &config.RuleConfig{Name: "test-rule", Output: config.Output{Format: config.OutputFormatRaw}}
+	testResults   = []result.Row{
+		{"user": "alice", "ip": "10.0.0.1"},
+		{"user": "bob", "ip": "10.0.0.2"},
+	}
+)
+
+func TestPublishStream(t *testing.T) {
+	alice, bob := findingUID(t, testResults[0]), findingUID(t, testResults[1])
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:   "JSONL",
+			format: FormatJSONL,
+			expected: fmt.Sprintf("{\"finding_uid\":%q,\"ip\":\"10.0.0.1\",\"user\":\"alice\"}\n{\"finding_uid\":%q,\"ip\":\"10.0.0.2\",\"user\":\"bob\"}\n",
+				alice, bob),
+		},
+		{
+			name:     "CSV",
+			format:   FormatCSV,
+			expected: fmt.Sprintf("finding_uid,ip,user\n%s,10.0.0.1,alice\n%s,10.0.0.2,bob\n", alice, bob),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			client, err := New(context.Background(), Config{Format: tt.format, Writer: &buf})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			if err := client.Publish(context.Background(), testResults, rawRuleConfig); err != nil {
+				t.Fatalf("Publish() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, buf.String()); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPublishSignalCSVFlattensFields(t *testing.T) {
+	var buf bytes.Buffer
+	client, err := New(context.Background(), Config{Format: FormatCSV, Writer: &buf})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	cfg := &config.RuleConfig{
+		Name: "test-rule",
+		Output: config.Output{
+			Format: config.OutputFormatSignal,
+			Fields: []config.OutputField{{Field: "ActorUserName", Source: "user"}},
+		},
+	}
+	if err := client.Publish(context.Background(), testResults[:1], cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	header := strings.Split(strings.SplitN(buf.String(), "\n", 2)[0], ",")
+	for _, want := range []string{"actor.user.name", "rule_name", "confidenceid"} {
+		if !isSubset([]string{want}, header) {
+			t.Errorf("expected column %q in header %v", want, header)
+		}
+	}
+}
+
+func TestAppendGzipWithRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "findings.csv.gz")
+	client, err := New(context.Background(), Config{Path: path, Format: FormatCSV, Gzip: true, RotateInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// Two batches within the same interval are appended to the same file with a single header.
+	for i := 0; i < 2; i++ {
+		if err := client.Publish(context.Background(), testResults, rawRuleConfig); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+	alice, bob := findingUID(t, testResults[0]), findingUID(t, testResults[1])
+	expected := fmt.Sprintf("finding_uid,ip,user\n%[1]s,10.0.0.1,alice\n%[2]s,10.0.0.2,bob\n%[1]s,10.0.0.1,alice\n%[2]s,10.0.0.2,bob\n", alice, bob)
+	if diff := cmp.Diff(expected, readGzip(t, path)); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	// A batch in the next interval rotates the file.
+	client.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := client.Publish(context.Background(), testResults[:1], rawRuleConfig); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	rotated, _ := filepath.Glob(filepath.Join(dir, "findings-*.csv.gz"))
+	if len(rotated) != 1 {
+		t.Fatalf("expected 1 rotated file, got %v", rotated)
+	}
+	if diff := cmp.Diff(expected, readGzip(t, rotated[0])); diff != "" {
+		t.Errorf("unexpected rotated output (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(fmt.Sprintf("finding_uid,ip,user\n%s,10.0.0.1,alice\n", alice), readGzip(t, path)); diff != "" {
+		t.Errorf("unexpected active output (-want +got):\n%s", diff)
+	}
+}
+
+func TestAppendSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "findings.jsonl")
+	client, err := New(context.Background(), Config{Path: path, Format: FormatJSONL, MaxSize: 10})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// Files rotated with the same modification time don't replace each other.
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			if err := os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatalf("failed to set modification time: %v", err)
+			}
+		}
+		if err := client.Publish(context.Background(), testResults, rawRuleConfig); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "findings*.jsonl"))
+	if len(files) != 3 {
+		t.Fatalf("expected the active and two rotated files, got %v", files)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	dir := t.TempDir()
+	client, err := New(context.Background(), Config{Path: filepath.Join(dir, "findings.parquet"), Format: FormatParquet, Gzip: true})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	// Batches written in the same second go to their own files.
+	now := time.Now()
+	client.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		if err := client.Publish(context.Background(), testResults, rawRuleConfig); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "findings-*.parquet"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 parquet files, got %v", files)
+	}
+	reader, err := file.OpenParquetFile(files[0], false)
+	if err != nil {
+		t.Fatalf("failed to open parquet file: %v", err)
+	}
+	defer reader.Close()
+	fr, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatalf("failed to create parquet reader: %v", err)
+	}
+	table, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatalf("failed to read parquet table: %v", err)
+	}
+	defer table.Release()
+
+	if table.NumRows() != int64(len(testResults)) || table.NumCols() != 3 {
+		t.Errorf("unexpected table shape: %d rows, %d columns", table.NumRows(), table.NumCols())
+	}
+}
+
+// findingUID returns the finding UID added to raw outputs of row.
+func findingUID(t *testing.T, row result.Row) string {
+	t.Helper()
+	uid, err := signal.FindingUID(row, rawRuleConfig)
+	if err != nil {
+		t.Fatalf("FindingUID() unexpected error: %v", err)
+	}
+	return uid
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("failed to read gzip %s: %v", path, err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to read gzip %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,19 @@
+package file
+
+import (
+	"io"
+	"time"
+)
+
+type Config struct {
+	Path   string
+	Format string // jsonl, csv or parquet
+	Gzip   bool
+	// MaxSize rotates the file once it has reached this many bytes. Zero disables size-based rotation.
+	MaxSize int64
+	// RotateInterval starts a new file whenever the current time enters a new interval (e.g. 24h for daily files).
+	// Zero disables time-based rotation.
+	RotateInterval time.Duration
+	// Writer, when set, receives the output instead of Path. Rotation and gzip do not apply.
+	Writer io.Writer
+}
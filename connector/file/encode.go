@@ -0,0 +1,151 @@
+package file
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"golang.org/x/exp/maps"
+)
+
+// writeJSONL writes one JSON document per line.
+func writeJSONL(w io.Writer, docs []any) error {
+	encoder := json.NewEncoder(w)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeCSV writes the rows using the given columns. The header is only written when writeHeader is set.
+func writeCSV(w io.Writer, rows []map[string]string, columns []string, writeHeader bool) error {
+	writer := csv.NewWriter(w)
+	if writeHeader {
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = row[col]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeParquet writes the rows as a single row group of nullable string columns.
+func writeParquet(w io.Writer, rows []map[string]string, columns []string, gzip bool) error {
+	fields := make([]arrow.Field, len(columns))
+	for i, col := range columns {
+		fields[i] = arrow.Field{Name: col, Type: arrow.BinaryTypes.String, Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	for _, row := range rows {
+		for i, col := range columns {
+			fb := builder.Field(i).(*array.StringBuilder)
+			if value, ok := row[col]; ok {
+				fb.Append(value)
+			} else {
+				fb.AppendNull()
+			}
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+
+	codec := compress.Codecs.Snappy
+	if gzip {
+		codec = compress.Codecs.Gzip
+	}
+	writer, err := pqarrow.NewFileWriter(schema, w, parquet.NewWriterProperties(parquet.WithCompression(codec)), pqarrow.DefaultWriterProps())
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	if err := writer.Write(record); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write parquet record: %w", err)
+	}
+	return writer.Close()
+}
+
+// flattenDocuments converts output documents to flat rows with dotted keys for nested objects.
+// Arrays are kept as JSON strings. It also returns the sorted union of all keys.
+func flattenDocuments(docs []any) ([]map[string]string, []string, error) {
+	var rows []map[string]string
+	columns := make(map[string]struct{})
+	for _, doc := range docs {
+		docJSON, err := json.Marshal(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode document: %w", err)
+		}
+		// Keep numbers as they were encoded instead of converting them to float64.
+		decoder := json.NewDecoder(bytes.NewReader(docJSON))
+		decoder.UseNumber()
+		var obj map[string]any
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+
+		row := make(map[string]string)
+		if err := flatten("", obj, row); err != nil {
+			return nil, nil, err
+		}
+		for key := range row {
+			columns[key] = struct{}{}
+		}
+		rows = append(rows, row)
+	}
+
+	keys := maps.Keys(columns)
+	sort.Strings(keys)
+	return rows, keys, nil
+}
+
+func flatten(prefix string, obj map[string]any, row map[string]string) error {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			if err := flatten(key, val, row); err != nil {
+				return err
+			}
+		case []any:
+			arrJSON, err := json.Marshal(val)
+			if err != nil {
+				return fmt.Errorf("failed to encode field %s: %w", key, err)
+			}
+			row[key] = string(arrJSON)
+		case string:
+			row[key] = val
+		case json.Number:
+			row[key] = val.String()
+		case nil:
+			row[key] = ""
+		default:
+			row[key] = fmt.Sprintf("%v", val)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package gchat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/nianticlabs/venator/connector/internal/chat"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"golang.org/x/exp/maps"
+)
+
+type Client struct {
+	WebhookURL string
+}
+
+const (
+	// Google Chat rejects messages larger than 32,000 bytes.
+	maxPayloadBytes = 32000
+	maxValueLength  = 1000
+)
+
+func New(ctx context.Context, config Config) (*Client, error) {
+	return &Client{
+		WebhookURL: config.WebhookURL,
+	}, nil
+}
+
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	// Keep as many findings as fit in the payload, noting the omission in the summary text.
+	payloadBytes, _, err := chat.Fit(len(results), maxPayloadBytes, func(n int) any {
+		return buildPayload(results, n, cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message to google chat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %v", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// buildPayload builds a message with a summary text followed by one cardsV2 card for each of the first n findings.
+func buildPayload(results []result.Row, n int, cfg *config.RuleConfig) map[string]interface{} {
+	text := fmt.Sprintf("*%d finding(s) generated by `%s` rule* (%s)", len(results), cfg.Name, chat.Levels(results, cfg))
+	if n < len(results) {
+		text += fmt.Sprintf("\n_%d finding(s) omitted due to message size limits._", len(results)-n)
+	}
+
+	var cards []map[string]interface{}
+	for i, r := range results[:n] {
+		flat := r.Flatten()
+		keys := maps.Keys(flat)
+		sort.Strings(keys)
+
+		var widgets []map[string]interface{}
+		for _, key := range keys {
+			widgets = append(widgets, map[string]interface{}{
+				"decoratedText": map[string]interface{}{
+					"topLabel": key,
+					"text":     chat.Truncate(flat[key], maxValueLength),
+					"wrapText": true,
+				},
+			})
+		}
+
+		cards = append(cards, map[string]interface{}{
+			"cardId": fmt.Sprintf("finding-%d", i+1),
+			"card": map[string]interface{}{
+				"header": map[string]interface{}{
+					"title":    fmt.Sprintf("Finding %d", i+1),
+					"subtitle": fmt.Sprintf("%s (%s)", cfg.Name, chat.Levels([]result.Row{r}, cfg)),
+				},
+				"sections": []map[string]interface{}{
+					{"widgets": widgets},
+				},
+			},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"text": text,
+	}
+	if len(cards) > 0 {
+		payload["cardsV2"] = cards
+	}
+	return payload
+}
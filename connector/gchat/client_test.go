@@ -0,0 +1,112 @@
+package gchat_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nianticlabs/venator/connector/gchat"
+	"github.com/nianticlabs/venator/connector/internal/chat/chattest"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []result.Row
+		wantCards   int
+		wantOmitted bool
+	}{
+		{
+			name: "all findings fit",
+			results: []result.Row{
+				{"user": "alice", "ip": "10.0.0.1"},
+				{"user": "bob", "ip": "10.0.0.2"},
+			},
+			wantCards: 2,
+		},
+		{
+			name:        "findings truncated over size limit",
+			results:     chattest.LargeResults(100),
+			wantCards:   0, // checked against the size limit below
+			wantOmitted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := gchat.New(context.Background(), gchat.Config{WebhookURL: server.URL})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			cfg := &config.RuleConfig{Name: "test-rule", Confidence: config.ConfidenceHigh}
+			if err := client.Publish(context.Background(), tt.results, cfg); err != nil {
+				t.Fatalf("Publish() unexpected error: %v", err)
+			}
+
+			var payload struct {
+				Text  string            `json:"text"`
+				Cards []json.RawMessage `json:"cardsV2"`
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			if len(body) > 32000 {
+				t.Errorf("payload size %d exceeds limit", len(body))
+			}
+			if tt.wantCards > 0 && len(payload.Cards) != tt.wantCards {
+				t.Errorf("expected %d cards, got %d", tt.wantCards, len(payload.Cards))
+			}
+			if omitted := strings.Contains(string(body), "omitted due to message size limits"); omitted != tt.wantOmitted {
+				t.Errorf("expected omitted note to be %v", tt.wantOmitted)
+			}
+			if !strings.Contains(payload.Text, "test-rule") || !strings.Contains(payload.Text, "high") {
+				t.Errorf("summary text does not mention the rule name and confidence: %q", payload.Text)
+			}
+		})
+	}
+}
+
+func TestPublishFindingLevels(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := gchat.New(context.Background(), gchat.Config{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	cfg := &config.RuleConfig{Name: "test-rule", Confidence: config.ConfidenceLow}
+	results := []result.Row{
+		{"user": "alice"},
+		{"user": "bob", "venator": map[string]any{"confidence": "high", "severity": "critical"}},
+	}
+	if err := client.Publish(context.Background(), results, cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"rule* (confidence: high, severity: critical)",
+		`"subtitle":"test-rule (confidence: low)"`,
+		`"subtitle":"test-rule (confidence: high, severity: critical)"`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected %q in payload %s", want, body)
+		}
+	}
+}
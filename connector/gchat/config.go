@@ -0,0 +1,5 @@
+package gchat
+
+type Config struct {
+	WebhookURL string
+}
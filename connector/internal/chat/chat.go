@@ -0,0 +1,71 @@
+// Package chat holds helpers shared by the publishers posting findings as chat messages.
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+// Fit marshals the payload built by build for the largest number of findings, up to total, whose
+// encoding is at most limit bytes, and returns it with that number. Payload sizes must grow with
+// the number of findings. It fails if even the payload without findings is too large.
+func Fit(total, limit int, build func(n int) any) ([]byte, int, error) {
+	marshal := func(n int) ([]byte, error) {
+		payload, err := json.Marshal(build(n))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		return payload, nil
+	}
+
+	payload, err := marshal(total)
+	if err != nil || len(payload) <= limit {
+		return payload, total, err
+	}
+	fitting, err := marshal(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(fitting) > limit {
+		return nil, 0, fmt.Errorf("payload of %d bytes exceeds the limit of %d bytes without any finding", len(fitting), limit)
+	}
+
+	// The payload with lo findings fits and the one with hi findings does not.
+	lo, hi := 0, total
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		payload, err := marshal(mid)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(payload) <= limit {
+			lo, fitting = mid, payload
+		} else {
+			hi = mid
+		}
+	}
+	return fitting, lo, nil
+}
+
+// Truncate shortens s to at most limit characters, ending it with an ellipsis if it was cut.
+func Truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// Levels describes the confidence and, if set, the severity of a finding, e.g. "confidence: high,
+// severity: critical". Given all findings of a message, it describes the highest levels.
+func Levels(rows []result.Row, cfg *config.RuleConfig) string {
+	text := fmt.Sprintf("confidence: %s", signal.HighestConfidence(rows, cfg))
+	if severity := signal.HighestSeverity(rows, cfg); severity != "" {
+		text += fmt.Sprintf(", severity: %s", severity)
+	}
+	return text
+}
@@ -0,0 +1,78 @@
+package chat_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nianticlabs/venator/connector/internal/chat"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestFit(t *testing.T) {
+	build := func(n int) any { return strings.Repeat("x", 10*n) }
+	tests := []struct {
+		name       string
+		total      int
+		limit      int
+		expectedN  int
+		errMessage string
+	}{
+		{name: "all findings fit", total: 5, limit: 100, expectedN: 5},
+		{name: "largest fitting number", total: 1000, limit: 505, expectedN: 50},
+		{name: "exact limit", total: 1000, limit: 502, expectedN: 50},
+		{name: "no finding fits", total: 10, limit: 5, expectedN: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, n, err := chat.Fit(tt.total, tt.limit, build)
+			if err != nil {
+				t.Fatalf("Fit() unexpected error: %v", err)
+			}
+			if n != tt.expectedN {
+				t.Errorf("expected %d findings, got %d", tt.expectedN, n)
+			}
+			if len(payload) > tt.limit || len(payload) != 10*n+2 {
+				t.Errorf("unexpected payload size %d", len(payload))
+			}
+		})
+	}
+}
+
+func TestFitTooLarge(t *testing.T) {
+	_, _, err := chat.Fit(3, 10, func(n int) any { return strings.Repeat("x", 20+n) })
+	if err == nil || !strings.Contains(err.Error(), "exceeds the limit of 10 bytes without any finding") {
+		t.Fatalf("expected an error, got %v", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := chat.Truncate("héllo", 10); got != "héllo" {
+		t.Errorf("Truncate() = %q, want the string unchanged", got)
+	}
+	if got := chat.Truncate("héllo world", 5); got != "héll…" {
+		t.Errorf("Truncate() = %q, want %q", got, "héll…")
+	}
+}
+
+func TestLevels(t *testing.T) {
+	cfg := &config.RuleConfig{Confidence: config.ConfidenceMedium}
+	tests := []struct {
+		name     string
+		rows     []result.Row
+		expected string
+	}{
+		{name: "rule confidence", rows: []result.Row{{"user": "alice"}}, expected: "confidence: medium"},
+		{name: "finding levels", rows: []result.Row{{"venator": map[string]any{"confidence": "high", "severity": "critical"}}}, expected: "confidence: high, severity: critical"},
+		{name: "highest levels", rows: []result.Row{{"user": "alice"}, {"venator.confidence": "high"}}, expected: "confidence: high"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chat.Levels(tt.rows, cfg); got != tt.expected {
+				t.Errorf("Levels() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package chattest provides test helpers for the chat message publishers.
+package chattest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// LargeResults returns n results of about 1 KB each, to exceed message size limits.
+func LargeResults(n int) []result.Row {
+	var results []result.Row
+	for i := 0; i < n; i++ {
+		results = append(results, result.Row{
+			"user":    fmt.Sprintf("user%d", i),
+			"message": strings.Repeat("x", 900),
+		})
+	}
+	return results
+}
@@ -0,0 +1,131 @@
+package objectstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5" // #nosec G501 -- used as an upload integrity check, not for security
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "connector/objectstore")
+
+type Client struct {
+	store  store
+	prefix string
+	now    func() time.Time
+}
+
+const (
+	ProviderS3  = "s3"
+	ProviderGCS = "gcs"
+
+	EncryptionSSES3  = "sse-s3"
+	EncryptionSSEKMS = "sse-kms"
+)
+
+func New(ctx context.Context, config Config) (*Client, error) {
+	switch config.Encryption {
+	case "", EncryptionSSES3:
+	case EncryptionSSEKMS:
+		if config.KMSKeyID == "" {
+			return nil, fmt.Errorf("encryption '%s' requires a KMS key ID", config.Encryption)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption '%s'", config.Encryption)
+	}
+
+	var s store
+	var err error
+	switch config.Provider {
+	case ProviderS3:
+		s, err = newS3Store(config)
+	case ProviderGCS:
+		s, err = newGCSStore(ctx, config)
+	default:
+		return nil, fmt.Errorf("unsupported object storage provider '%s'", config.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		store:  s,
+		prefix: config.Prefix,
+		now:    time.Now,
+	}, nil
+}
+
+// Publish uploads all results of the run as a single gzipped JSONL object.
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, r := range results {
+		output, err := signal.BuildOutput(r, cfg)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(output); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	body := buf.Bytes()
+	md5Sum := md5.Sum(body) // #nosec G401
+	sha256Sum := sha256.Sum256(body)
+	key := objectKey(c.prefix, cfg.UID, c.now(), uuid.NewString())
+
+	err := c.store.Put(ctx, object{
+		Key:  key,
+		Body: body,
+		// Stored as a gzip file rather than with Content-Encoding, so that
+		// providers don't transcode it and the body matches content-sha256.
+		ContentType: "application/gzip",
+		MD5:         md5Sum[:],
+		Metadata: map[string]string{
+			"rule-name":      cfg.Name,
+			"rule-uid":       cfg.UID,
+			"record-count":   fmt.Sprintf("%d", len(results)),
+			"content-sha256": hex.EncodeToString(sha256Sum[:]),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+
+	logger.Infof("uploaded %d finding(s) to %s", len(results), key)
+	return nil
+}
+
+// objectKey builds a Hive-style partitioned key: <prefix>/rule_uid=<uid>/dt=<YYYY-MM-DD>/<run_id>.jsonl.gz
+// The rule UID is path-escaped so that it always stays a single key segment.
+func objectKey(prefix, ruleUID string, t time.Time, runID string) string {
+	if ruleUID == "" {
+		ruleUID = "unknown"
+	}
+	return path.Join(prefix,
+		"rule_uid="+url.PathEscape(ruleUID),
+		"dt="+t.UTC().Format(time.DateOnly),
+		runID+".jsonl.gz")
+}
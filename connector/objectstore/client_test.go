@@ -0,0 +1,123 @@
+package objectstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+type fakeStore struct {
+	objects []object
+}
+
+func (f *fakeStore) Put(ctx context.Context, obj object) error {
+	f.objects = append(f.objects, obj)
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	fs := &fakeStore{}
+	client := &Client{
+		store:  fs,
+		prefix: "findings",
+		now:    func() time.Time { return time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC) },
+	}
+	cfg := &config.RuleConfig{Name: "test-rule", UID: "test-uid", Output: config.Output{Format: config.OutputFormatRaw}}
+	results := []result.Row{{"user": "alice"}, {"user": "bob"}}
+
+	if err := client.Publish(context.Background(), results, cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if len(fs.objects) != 1 {
+		t.Fatalf("expected a single object per run, got %d", len(fs.objects))
+	}
+	obj := fs.objects[0]
+
+	keyPattern := regexp.MustCompile(`^findings/rule_uid=test-uid/dt=2024-03-01/[0-9a-f-]{36}\.jsonl\.gz$`)
+	if !keyPattern.MatchString(obj.Key) {
+		t.Errorf("unexpected object key %q", obj.Key)
+	}
+
+	if obj.ContentType != "application/gzip" {
+		t.Errorf("unexpected content type %q", obj.ContentType)
+	}
+
+	sum := sha256.Sum256(obj.Body)
+	if obj.Metadata["content-sha256"] != hex.EncodeToString(sum[:]) || len(obj.MD5) != 16 {
+		t.Errorf("unexpected content hashes in %+v", obj.Metadata)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(obj.Body))
+	if err != nil {
+		t.Fatalf("object body is not gzipped: %v", err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to decompress object body: %v", err)
+	}
+	var expected strings.Builder
+	for _, r := range results {
+		uid, err := signal.FindingUID(r, cfg)
+		if err != nil {
+			t.Fatalf("FindingUID() unexpected error: %v", err)
+		}
+		fmt.Fprintf(&expected, "{\"finding_uid\":%q,\"user\":%q}\n", uid, r["user"])
+	}
+	if diff := cmp.Diff(expected.String(), string(content)); diff != "" {
+		t.Errorf("unexpected object content (-want +got):\n%s", diff)
+	}
+}
+
+func TestObjectKey(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		ruleUID  string
+		expected string
+	}{
+		{name: "plain uid", ruleUID: "abc-123", expected: "findings/rule_uid=abc-123/dt=2024-03-01/run.jsonl.gz"},
+		{name: "empty uid", ruleUID: "", expected: "findings/rule_uid=unknown/dt=2024-03-01/run.jsonl.gz"},
+		{name: "slash", ruleUID: "a/b", expected: "findings/rule_uid=a%2Fb/dt=2024-03-01/run.jsonl.gz"},
+		{name: "traversal", ruleUID: "../../other", expected: "findings/rule_uid=..%2F..%2Fother/dt=2024-03-01/run.jsonl.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := objectKey("findings", tt.ruleUID, now, "run"); got != tt.expected {
+				t.Errorf("objectKey() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "unsupported provider", cfg: Config{Provider: "azure", Bucket: "b"}},
+		{name: "unsupported encryption", cfg: Config{Provider: ProviderS3, Endpoint: "localhost:9000", Bucket: "b", Encryption: "sse-c"}},
+		{name: "kms without key", cfg: Config{Provider: ProviderS3, Endpoint: "localhost:9000", Bucket: "b", Encryption: EncryptionSSEKMS}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(context.Background(), tt.cfg); err == nil {
+				t.Fatalf("expected an error but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package objectstore
+
+type Config struct {
+	Provider string // s3 or gcs
+	Bucket   string
+	Prefix   string
+
+	// S3-compatible storage only. Credentials fall back to the AWS environment and instance role when empty.
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	DisableTLS      bool
+
+	// Encryption is one of "" (provider default), "sse-s3" or "sse-kms".
+	Encryption string
+	KMSKeyID   string
+}
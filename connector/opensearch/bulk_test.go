@@ -0,0 +1,39 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func TestBuildBulkRequestBodyUsesFindingUID(t *testing.T) {
+	cfg := &config.RuleConfig{UID: "test-uid", Output: config.Output{Format: config.OutputFormatRaw}}
+	results := []result.Row{{"user": "alice"}, {"user": "bob"}}
+
+	body, err := buildBulkRequestBody(results, cfg)
+	if err != nil {
+		t.Fatalf("buildBulkRequestBody() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if len(lines) != 2*len(results) {
+		t.Fatalf("expected %d lines, got %d", 2*len(results), len(lines))
+	}
+	for i, r := range results {
+		var op BulkRequestOp
+		if err := json.Unmarshal([]byte(lines[2*i]), &op); err != nil {
+			t.Fatalf("failed to decode action line: %v", err)
+		}
+		uid, err := signal.FindingUID(r, cfg)
+		if err != nil {
+			t.Fatalf("FindingUID() unexpected error: %v", err)
+		}
+		if op.Create == nil || op.Create.ID != uid || op.Create.Index != outputIndexName {
+			t.Errorf("unexpected action %s, want _id %s", lines[2*i], uid)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/opensearch-project/opensearch-go/v2"
 
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 	"github.com/nianticlabs/venator/internal/signal"
 )
 
@@ -52,7 +53,7 @@ func New(ctx context.Context, config Config) (*Client, error) {
 	}, nil
 }
 
-func (c *Client) Query(ctx context.Context, cfg *config.RuleConfig) ([]map[string]string, error) {
+func (c *Client) Query(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error) {
 	body, err := json.Marshal(map[string]string{"query": cfg.Query})
 	if err != nil {
 		return nil, err
@@ -87,14 +88,16 @@ func (c *Client) Query(ctx context.Context, cfg *config.RuleConfig) ([]map[strin
 	}
 
 	var response QueryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
 		return nil, fmt.Errorf("error decoding QueryResponse: %w", err)
 	}
-	var results []map[string]string
+	var results []result.Row
 	for _, row := range response.Datarows {
-		res := make(map[string]string)
+		res := make(result.Row)
 		for i, col := range response.Schema {
-			value := fmt.Sprintf("%v", row[i])
+			value := result.Normalize(row[i])
 			if _, exists := col["alias"]; exists {
 				res[col["alias"]] = value
 			} else {
@@ -107,7 +110,7 @@ func (c *Client) Query(ctx context.Context, cfg *config.RuleConfig) ([]map[strin
 	return results, nil
 }
 
-func (c *Client) Publish(ctx context.Context, results []map[string]string, cfg *config.RuleConfig) error {
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
 	if len(results) == 0 {
 		return nil
 	}
@@ -139,7 +142,7 @@ func (c *Client) Publish(ctx context.Context, results []map[string]string, cfg *
 	return errors.Join(errArr...)
 }
 
-func buildBulkRequestBody(results []map[string]string, cfg *config.RuleConfig) (string, error) {
+func buildBulkRequestBody(results []result.Row, cfg *config.RuleConfig) (string, error) {
 	var body strings.Builder
 
 	for _, r := range results {
@@ -152,10 +155,17 @@ func buildBulkRequestBody(results []map[string]string, cfg *config.RuleConfig) (
 		if err != nil {
 			return "", err
 		}
+		// The finding UID as document ID makes create fail with a conflict instead of indexing
+		// a duplicate when a run is retried.
+		uid, err := signal.FindingUID(r, cfg)
+		if err != nil {
+			return "", err
+		}
 
 		bReq := BulkRequestOp{
 			Create: &CreateReq{
 				Index: outputIndexName,
+				ID:    uid,
 			},
 		}
 		jsonBytes, err := json.Marshal(bReq)
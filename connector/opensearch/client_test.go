@@ -20,6 +20,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/nianticlabs/venator/connector/opensearch"
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 )
 
 const (
@@ -124,7 +125,7 @@ func TestPublishIntegration(t *testing.T) {
 				},
 			}
 
-			err := client.Publish(ctx, tt.data, cfg)
+			err := client.Publish(ctx, result.FromStringMaps(tt.data), cfg)
 			if !errors.Is(err, tt.err) {
 				t.Fatalf("expected error: %v, got: %v", tt.err, err)
 			}
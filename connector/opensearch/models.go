@@ -29,6 +29,7 @@ type QueryError struct {
 
 type CreateReq struct {
 	Index string `json:"_index"`
+	ID    string `json:"_id,omitempty"`
 }
 
 type BulkRequestOp struct {
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 	"github.com/nianticlabs/venator/internal/signal"
 	"github.com/sirupsen/logrus"
 )
@@ -15,72 +17,89 @@ import (
 var logger = logrus.StandardLogger().WithField("pkg", "connector/pubsub")
 
 type Client struct {
-	projectID string
-	topicID   string
+	client           *pubsub.Client
+	topic            *pubsub.Topic
+	orderingKeyField string
 }
 
 func New(ctx context.Context, config Config) (*Client, error) {
+	client, err := pubsub.NewClient(ctx, config.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := client.Topic(config.TopicID)
+	if ok, err := topic.Exists(ctx); err != nil {
+		client.Close()
+		return nil, err
+	} else if !ok {
+		client.Close()
+		return nil, fmt.Errorf("topic %s doesn't exist", config.TopicID)
+	}
+
+	if config.DelayThreshold > 0 {
+		topic.PublishSettings.DelayThreshold = config.DelayThreshold
+	}
+	if config.CountThreshold > 0 {
+		topic.PublishSettings.CountThreshold = config.CountThreshold
+	}
+	if config.ByteThreshold > 0 {
+		topic.PublishSettings.ByteThreshold = config.ByteThreshold
+	}
+	topic.EnableMessageOrdering = config.OrderingKeyField != ""
+
 	return &Client{
-		projectID: config.ProjectID,
-		topicID:   config.TopicID,
+		client:           client,
+		topic:            topic,
+		orderingKeyField: config.OrderingKeyField,
 	}, nil
 }
 
-func (c *Client) Publish(ctx context.Context, results []map[string]string, cfg *config.RuleConfig) error {
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
 	if len(results) == 0 {
 		return nil
 	}
 
-	client, err := pubsub.NewClient(ctx, c.projectID)
-	if err != nil {
-		return err
+	// Publish never blocks; messages are batched according to the topic's PublishSettings.
+	type pending struct {
+		result      *pubsub.PublishResult
+		orderingKey string
 	}
-	defer client.Close()
-
-	topic := client.Topic(c.topicID)
-	if ok, err := topic.Exists(ctx); err != nil {
-		return err
-	} else if !ok {
-		return fmt.Errorf("topic %s doesn't exist", c.topicID)
-	}
-
-	pubResults := make(chan *pubsub.PublishResult)
-	go func() {
-		defer close(pubResults)
-		for _, r := range results {
-			msg, err := buildPubSubMessage(r, cfg)
-			if err != nil {
-				logger.Errorf("failed to build pubsub message: %v\n", err)
-				pubResults <- nil
-				continue
-			}
-			res := topic.Publish(ctx, msg)
-			pubResults <- res
-		}
-	}()
-
+	var pendings []pending
 	var pubErrors []error
-	for res := range pubResults {
-		if res == nil {
+	for _, r := range results {
+		msg, err := c.buildPubSubMessage(r, cfg)
+		if err != nil {
+			// The other findings are still published, but the run fails so the finding isn't lost.
+			logger.Errorf("failed to build pubsub message: %v", err)
+			pubErrors = append(pubErrors, fmt.Errorf("build: %w", err))
 			continue
 		}
-		id, err := res.Get(ctx)
+		pendings = append(pendings, pending{result: c.topic.Publish(ctx, msg), orderingKey: msg.OrderingKey})
+	}
+
+	for _, p := range pendings {
+		id, err := p.result.Get(ctx)
 		if err != nil {
-			logger.Errorf("failed to publish message %v: %v\n", id, err)
-			pubErrors = append(pubErrors, err)
+			logger.Errorf("failed to publish message %v: %v", id, err)
+			pubErrors = append(pubErrors, fmt.Errorf("get: %w", err))
+			// A failed publish pauses its ordering key until explicitly resumed.
+			if p.orderingKey != "" {
+				c.topic.ResumePublish(p.orderingKey)
+			}
 			continue
 		}
-		logger.Infof("published message with msg ID: %v\n", id)
+		logger.Infof("published message with msg ID: %v", id)
 	}
 	if len(pubErrors) != 0 {
-		return fmt.Errorf("get: %w", errors.Join(pubErrors...))
+		return fmt.Errorf("failed to publish %d of %d finding(s): %w", len(pubErrors), len(results), errors.Join(pubErrors...))
 	}
 
 	return nil
 }
 
-func buildPubSubMessage(result map[string]string, cfg *config.RuleConfig) (*pubsub.Message, error) {
-	output, err := signal.BuildOutput(result, cfg)
+func (c *Client) buildPubSubMessage(row result.Row, cfg *config.RuleConfig) (*pubsub.Message, error) {
+	output, err := signal.BuildOutput(row, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +109,35 @@ func buildPubSubMessage(result map[string]string, cfg *config.RuleConfig) (*pubs
 		return nil, err
 	}
 
-	return &pubsub.Message{
-		Data: dataJSON,
-	}, nil
+	uid, err := signal.FindingUID(row, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &pubsub.Message{
+		Data:       dataJSON,
+		Attributes: buildAttributes(row, cfg),
+	}
+	msg.Attributes[signal.FindingUIDField] = uid
+	if c.orderingKeyField != "" {
+		msg.OrderingKey, _ = row.GetString(c.orderingKeyField)
+	}
+	return msg, nil
+}
+
+// buildAttributes returns the rule metadata and the confidence and severity of the finding attached
+// to every message, so subscribers can filter on them.
+func buildAttributes(row result.Row, cfg *config.RuleConfig) map[string]string {
+	attrs := map[string]string{
+		"rule_id":    cfg.UID,
+		"rule_name":  cfg.Name,
+		"confidence": string(signal.FindingConfidence(row, cfg)),
+	}
+	if severity := signal.FindingSeverity(row, cfg); severity != "" {
+		attrs["severity"] = string(severity)
+	}
+	if len(cfg.Tags) > 0 {
+		attrs["tags"] = strings.Join(cfg.Tags, ",")
+	}
+	return attrs
 }
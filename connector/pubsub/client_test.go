@@ -0,0 +1,154 @@
+package pubsub_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/connector/pubsub"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+const (
+	projectID = "test-project"
+	topicID   = "test-topic"
+)
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+
+	if _, err := srv.GServer.CreateTopic(ctx, &pubsubpb.Topic{Name: "projects/" + projectID + "/topics/" + topicID}); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+
+	if _, err := pubsub.New(ctx, pubsub.Config{ProjectID: projectID, TopicID: "missing-topic"}); err == nil {
+		t.Fatalf("expected an error for a missing topic")
+	}
+
+	client, err := pubsub.New(ctx, pubsub.Config{ProjectID: projectID, TopicID: topicID, CountThreshold: 10, OrderingKeyField: "user"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	cfg := &config.RuleConfig{
+		Name:       "test-rule",
+		UID:        "test-uid",
+		Confidence: config.ConfidenceMedium,
+		Tags:       []string{"test", "identity"},
+		Output:     config.Output{Format: config.OutputFormatRaw},
+	}
+	results := []result.Row{{"user": "alice"}, {"user": "bob"}}
+
+	// Publish twice to make sure the client and topic are reused across calls.
+	for i := 0; i < 2; i++ {
+		if err := client.Publish(ctx, results, cfg); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(msgs))
+	}
+	expectedAttrs := map[string]string{
+		"rule_id":    "test-uid",
+		"rule_name":  "test-rule",
+		"confidence": "medium",
+		"tags":       "test,identity",
+	}
+	for _, msg := range msgs {
+		// Retried findings carry the same finding UID.
+		uid, err := signal.FindingUID(result.Row{"user": msg.OrderingKey}, cfg)
+		if err != nil {
+			t.Fatalf("FindingUID() unexpected error: %v", err)
+		}
+		expectedAttrs["finding_uid"] = uid
+		if diff := cmp.Diff(expectedAttrs, msg.Attributes); diff != "" {
+			t.Errorf("unexpected attributes (-want +got):\n%s", diff)
+		}
+		if msg.OrderingKey != "alice" && msg.OrderingKey != "bob" {
+			t.Errorf("unexpected ordering key %q", msg.OrderingKey)
+		}
+	}
+}
+
+func TestPublishBuildError(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+
+	if _, err := srv.GServer.CreateTopic(ctx, &pubsubpb.Topic{Name: "projects/" + projectID + "/topics/" + topicID}); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	client, err := pubsub.New(ctx, pubsub.Config{ProjectID: projectID, TopicID: topicID})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// The fingerprint window requires a valid timestamp to derive the finding UID.
+	cfg := &config.RuleConfig{
+		Name:        "test-rule",
+		UID:         "test-uid",
+		Fingerprint: &config.Fingerprint{Window: time.Hour},
+		Output: config.Output{
+			Format: config.OutputFormatRaw,
+			Fields: []config.OutputField{{Field: "timestamp", Source: "ts"}},
+		},
+	}
+	results := []result.Row{
+		{"user": "alice", "ts": "2024-03-01T12:00:00Z"},
+		{"user": "bob", "ts": "not a timestamp"},
+	}
+	err = client.Publish(ctx, results, cfg)
+	if err == nil || !strings.Contains(err.Error(), "failed to publish 1 of 2 finding(s)") {
+		t.Fatalf("expected a build error, got %v", err)
+	}
+	if msgs := srv.Messages(); len(msgs) != 1 {
+		t.Errorf("expected the valid finding to be published, got %d message(s)", len(msgs))
+	}
+}
+
+func TestPublishFindingLevels(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+
+	if _, err := srv.GServer.CreateTopic(ctx, &pubsubpb.Topic{Name: "projects/" + projectID + "/topics/" + topicID}); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	client, err := pubsub.New(ctx, pubsub.Config{ProjectID: projectID, TopicID: topicID})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	cfg := &config.RuleConfig{
+		Name:       "test-rule",
+		UID:        "test-uid",
+		Confidence: config.ConfidenceLow,
+		Output:     config.Output{Format: config.OutputFormatRaw},
+	}
+	results := []result.Row{{"user": "alice", "venator": map[string]any{"confidence": "high", "severity": "critical"}}}
+	if err := client.Publish(ctx, results, cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if got := msgs[0].Attributes; got["confidence"] != "high" || got["severity"] != "critical" {
+		t.Errorf("expected the finding confidence and severity, got %v", got)
+	}
+}
@@ -1,6 +1,29 @@
 package pubsub
 
+import "time"
+
 type Config struct {
 	ProjectID string
 	TopicID   string
+
+	// Batching settings. Zero values keep the client library defaults.
+	DelayThreshold time.Duration
+	CountThreshold int
+	ByteThreshold  int
+
+	// OrderingKeyField is the result field used as the message ordering key. Ordering is disabled when empty.
+	OrderingKeyField string
+}
+
+type SubscriberConfig struct {
+	ProjectID      string
+	SubscriptionID string
+
+	// MaxMessages caps the number of messages pulled per run.
+	MaxMessages int
+	// Window is how long to wait for messages before returning what was pulled.
+	Window time.Duration
+	// AckDeadline is how long pulled messages stay leased while their results are processed, at
+	// most 10 minutes, the Pub/Sub maximum. Messages of runs taking longer are redelivered.
+	AckDeadline time.Duration
 }
@@ -0,0 +1,203 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	vkit "cloud.google.com/go/pubsub/apiv1"
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// Subscriber is a QueryRunner that pulls messages from a subscription instead of running a query.
+// Pulled messages are leased until Ack or Nack is called, so they are only removed from the
+// subscription once the results were handled.
+type Subscriber struct {
+	client       *vkit.SubscriberClient
+	subscription string
+	maxMessages  int
+	window       time.Duration
+	ackDeadline  time.Duration
+
+	mu      sync.Mutex
+	pending []string // ack IDs of the last Query
+}
+
+const (
+	defaultMaxMessages = 1000
+	defaultWindow      = 30 * time.Second
+	defaultAckDeadline = 10 * time.Minute
+	// maxAckDeadline is the longest ack deadline Pub/Sub accepts.
+	maxAckDeadline = 10 * time.Minute
+	maxPullBatch   = 1000
+)
+
+func NewSubscriber(ctx context.Context, config SubscriberConfig) (*Subscriber, error) {
+	if config.AckDeadline > maxAckDeadline {
+		return nil, fmt.Errorf("ack deadline %s exceeds the Pub/Sub maximum of %s", config.AckDeadline, maxAckDeadline)
+	}
+
+	var opts []option.ClientOption
+	// Match pubsub.NewClient, which connects to the emulator when this variable is set.
+	if addr := os.Getenv("PUBSUB_EMULATOR_HOST"); addr != "" {
+		conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("grpc.Dial: %w", err)
+		}
+		opts = append(opts, option.WithGRPCConn(conn), option.WithTelemetryDisabled())
+	}
+
+	client, err := vkit.NewSubscriberClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscriber{
+		client:       client,
+		subscription: fmt.Sprintf("projects/%s/subscriptions/%s", config.ProjectID, config.SubscriptionID),
+		maxMessages:  config.MaxMessages,
+		window:       config.Window,
+		ackDeadline:  config.AckDeadline,
+	}
+	if s.maxMessages <= 0 {
+		s.maxMessages = defaultMaxMessages
+	}
+	if s.window <= 0 {
+		s.window = defaultWindow
+	}
+	if s.ackDeadline <= 0 {
+		s.ackDeadline = defaultAckDeadline
+	}
+
+	if _, err := client.GetSubscription(ctx, &pubsubpb.GetSubscriptionRequest{Subscription: s.subscription}); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("subscription %s is not accessible: %w", config.SubscriptionID, err)
+	}
+	return s, nil
+}
+
+// Query pulls up to the configured number of messages, or as many as arrive before the window closes,
+// and decodes their JSON data into result rows. The rule's query is not used. On failure, the
+// messages already pulled are nacked, since the caller won't handle them.
+func (s *Subscriber) Query(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pullCtx, cancel := context.WithTimeout(ctx, s.window)
+	defer cancel()
+
+	var results []result.Row
+	pulled := 0
+	for pulled < s.maxMessages {
+		resp, err := s.client.Pull(pullCtx, &pubsubpb.PullRequest{
+			Subscription: s.subscription,
+			MaxMessages:  int32(min(s.maxMessages-pulled, maxPullBatch)),
+		})
+		if err != nil {
+			if pullCtx.Err() != nil || status.Code(err) == codes.DeadlineExceeded {
+				break
+			}
+			return nil, errors.Join(fmt.Errorf("failed to pull messages: %w", err), s.nack(ctx))
+		}
+		if len(resp.ReceivedMessages) == 0 {
+			continue
+		}
+
+		var ackIDs []string
+		for _, received := range resp.ReceivedMessages {
+			ackIDs = append(ackIDs, received.AckId)
+			row, err := decodeMessage(received.Message.Data)
+			if err != nil {
+				// Malformed messages would never succeed, so they are acknowledged with the rest.
+				logger.Warnf("skipping message %s: %v", received.Message.MessageId, err)
+				continue
+			}
+			results = append(results, row)
+		}
+		s.pending = append(s.pending, ackIDs...)
+		pulled += len(ackIDs)
+
+		// Keep the messages leased while the results go through exclusions and publishers.
+		if err := s.client.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+			Subscription:       s.subscription,
+			AckIds:             ackIDs,
+			AckDeadlineSeconds: int32(s.ackDeadline.Seconds()),
+		}); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to extend ack deadline: %w", err), s.nack(ctx))
+		}
+	}
+
+	logger.Infof("pulled %d message(s) from %s", pulled, s.subscription)
+	return results, nil
+}
+
+// Ack acknowledges all messages returned by the last Query.
+func (s *Subscriber) Ack(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, batch := range batches(s.pending, maxPullBatch) {
+		if err := s.client.Acknowledge(ctx, &pubsubpb.AcknowledgeRequest{
+			Subscription: s.subscription,
+			AckIds:       batch,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.pending = nil
+	return errors.Join(errs...)
+}
+
+// Nack makes all messages returned by the last Query available for redelivery.
+func (s *Subscriber) Nack(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nack(ctx)
+}
+
+func (s *Subscriber) nack(ctx context.Context) error {
+	var errs []error
+	for _, batch := range batches(s.pending, maxPullBatch) {
+		if err := s.client.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+			Subscription:       s.subscription,
+			AckIds:             batch,
+			AckDeadlineSeconds: 0,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.pending = nil
+	return errors.Join(errs...)
+}
+
+// decodeMessage converts a JSON object into a result row. Nested objects are kept, so signals
+// published by other rules can be mapped by dotted paths such as actor.user.name.
+func decodeMessage(data []byte) (result.Row, error) {
+	row, err := result.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("message data is not a JSON object: %w", err)
+	}
+	return row, nil
+}
+
+func batches(ids []string, size int) [][]string {
+	var out [][]string
+	for len(ids) > 0 {
+		n := min(size, len(ids))
+		out = append(out, ids[:n])
+		ids = ids[n:]
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package pubsub_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/connector/pubsub"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+const subscriptionID = "test-subscription"
+
+func TestSubscriberQuery(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+
+	topic := "projects/" + projectID + "/topics/" + topicID
+	if _, err := srv.GServer.CreateTopic(ctx, &pubsubpb.Topic{Name: topic}); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	if _, err := srv.GServer.CreateSubscription(ctx, &pubsubpb.Subscription{
+		Name:               "projects/" + projectID + "/subscriptions/" + subscriptionID,
+		Topic:              topic,
+		AckDeadlineSeconds: 10,
+	}); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	srv.Publish(topic, []byte(`{"rule_name": "stage-1", "actor": {"user": {"name": "alice"}}, "count": 3}`), nil)
+	srv.Publish(topic, []byte(`{"rule_name": "stage-1", "actor": {"user": {"name": "bob"}}, "ttps": ["T1078"]}`), nil)
+	srv.Publish(topic, []byte(`not json`), nil)
+
+	subscriber, err := pubsub.NewSubscriber(ctx, pubsub.SubscriberConfig{
+		ProjectID:      projectID,
+		SubscriptionID: subscriptionID,
+		MaxMessages:    10,
+		Window:         500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewSubscriber() unexpected error: %v", err)
+	}
+
+	expected := []result.Row{
+		{"rule_name": "stage-1", "actor": map[string]any{"user": map[string]any{"name": "alice"}}, "count": int64(3)},
+		{"rule_name": "stage-1", "actor": map[string]any{"user": map[string]any{"name": "bob"}}, "ttps": []any{"T1078"}},
+	}
+	sortRows := cmp.Transformer("sort", func(rows []result.Row) []result.Row {
+		sorted := append([]result.Row{}, rows...)
+		sort.Slice(sorted, func(i, j int) bool {
+			a, _ := sorted[i].GetString("actor.user.name")
+			b, _ := sorted[j].GetString("actor.user.name")
+			return a < b
+		})
+		return sorted
+	})
+
+	cfg := &config.RuleConfig{Name: "stage-2"}
+	results, err := subscriber.Query(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, results, sortRows); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+
+	// Nacked messages are redelivered on the next run.
+	if err := subscriber.Nack(ctx); err != nil {
+		t.Fatalf("Nack() unexpected error: %v", err)
+	}
+	results, err = subscriber.Query(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, results, sortRows); diff != "" {
+		t.Errorf("unexpected redelivered results (-want +got):\n%s", diff)
+	}
+
+	// Acked messages, including the malformed one, are gone.
+	if err := subscriber.Ack(ctx); err != nil {
+		t.Fatalf("Ack() unexpected error: %v", err)
+	}
+	for _, msg := range srv.Messages() {
+		if msg.Acks != 1 {
+			t.Errorf("expected message %s to be acked once, got %d", msg.ID, msg.Acks)
+		}
+	}
+	results, err = subscriber.Query(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results after ack, got %v", results)
+	}
+}
+
+func TestNewSubscriberAckDeadline(t *testing.T) {
+	_, err := pubsub.NewSubscriber(context.Background(), pubsub.SubscriberConfig{
+		ProjectID:      projectID,
+		SubscriptionID: subscriptionID,
+		AckDeadline:    11 * time.Minute,
+	})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the Pub/Sub maximum") {
+		t.Fatalf("expected an ack deadline error, got %v", err)
+	}
+}
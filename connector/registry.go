@@ -4,11 +4,18 @@ package connector
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/nianticlabs/venator/connector/bigquery"
+	"github.com/nianticlabs/venator/connector/file"
+	"github.com/nianticlabs/venator/connector/gchat"
+	"github.com/nianticlabs/venator/connector/objectstore"
 	"github.com/nianticlabs/venator/connector/opensearch"
 	"github.com/nianticlabs/venator/connector/pubsub"
 	"github.com/nianticlabs/venator/connector/slack"
+	"github.com/nianticlabs/venator/connector/syslog"
+	"github.com/nianticlabs/venator/connector/teams"
+	"github.com/nianticlabs/venator/connector/thehive"
 	"github.com/nianticlabs/venator/internal/config"
 
 	"github.com/sirupsen/logrus"
@@ -33,6 +40,13 @@ func NewRegistry(ctx context.Context, globalCfg *config.GlobalConfig) *Registry
 	r.initPubSub(ctx, globalCfg.PubSub)
 	r.initBigQuery(ctx, globalCfg.BigQuery)
 	r.initSlack(ctx, globalCfg.Slack)
+	r.initSyslog(ctx, globalCfg.Syslog)
+	r.initTeams(ctx, globalCfg.Teams)
+	r.initGChat(ctx, globalCfg.GChat)
+	r.initFile(ctx, globalCfg.File)
+	r.initStdout(ctx, globalCfg.Stdout)
+	r.initObjectStore(ctx, globalCfg.ObjectStore)
+	r.initTheHive(ctx, globalCfg.TheHive)
 
 	return r
 }
@@ -76,23 +90,44 @@ func (r *Registry) initPubSub(ctx context.Context, connectors config.PubSubConne
 
 	for name, psCfg := range connectors.Instances {
 		// Validate required fields
-		if psCfg.ProjectID == "" || psCfg.TopicID == "" {
+		if psCfg.ProjectID == "" || (psCfg.TopicID == "" && psCfg.SubscriptionID == "") {
 			logger.Warnf("Missing required fields for PubSub instance '%s'. Skipping initialization.", name)
 			continue
 		}
 
-		client, err := pubsub.New(ctx, pubsub.Config{
-			ProjectID: psCfg.ProjectID,
-			TopicID:   psCfg.TopicID,
-		})
-		if err != nil {
-			logger.Warnf("Error creating PubSub instance '%s': %v. Skipping initialization.", name, err)
-			continue
+		instanceName := "pubsub." + name
+		if psCfg.TopicID != "" {
+			client, err := pubsub.New(ctx, pubsub.Config{
+				ProjectID:        psCfg.ProjectID,
+				TopicID:          psCfg.TopicID,
+				DelayThreshold:   psCfg.DelayThreshold,
+				CountThreshold:   psCfg.CountThreshold,
+				ByteThreshold:    psCfg.ByteThreshold,
+				OrderingKeyField: psCfg.OrderingKeyField,
+			})
+			if err != nil {
+				logger.Warnf("Error creating PubSub instance '%s': %v. Skipping initialization.", name, err)
+				continue
+			}
+			r.publishers[instanceName] = client
+			logger.Infof("Initialized PubSub instance '%s' as Publisher.", name)
 		}
 
-		instanceName := "pubsub." + name
-		r.publishers[instanceName] = client
-		logger.Infof("Initialized PubSub instance '%s' as Publisher.", name)
+		if psCfg.SubscriptionID != "" {
+			subscriber, err := pubsub.NewSubscriber(ctx, pubsub.SubscriberConfig{
+				ProjectID:      psCfg.ProjectID,
+				SubscriptionID: psCfg.SubscriptionID,
+				MaxMessages:    psCfg.MaxMessages,
+				Window:         psCfg.Window,
+				AckDeadline:    psCfg.AckDeadline,
+			})
+			if err != nil {
+				logger.Warnf("Error creating PubSub subscriber '%s': %v. Skipping initialization.", name, err)
+				continue
+			}
+			r.queryRunners[instanceName] = subscriber
+			logger.Infof("Initialized PubSub instance '%s' as QueryRunner.", name)
+		}
 	}
 }
 
@@ -110,9 +145,15 @@ func (r *Registry) initBigQuery(ctx context.Context, connectors config.BigQueryC
 		}
 
 		client, err := bigquery.New(ctx, bigquery.Config{
-			ProjectID: bqCfg.ProjectID,
-			DatasetID: bqCfg.DatasetID,
-			TableID:   bqCfg.TableID,
+			ProjectID:        bqCfg.ProjectID,
+			DatasetID:        bqCfg.DatasetID,
+			TableID:          bqCfg.TableID,
+			CreateTable:      bqCfg.CreateTable,
+			StorageWriteAPI:  bqCfg.StorageWriteAPI,
+			BatchSize:        bqCfg.BatchSize,
+			Location:         bqCfg.Location,
+			DryRunBudget:     bqCfg.DryRunBudget,
+			RejectOverBudget: bqCfg.RejectOverBudget,
 		})
 		if err != nil {
 			logger.Warnf("Error creating BigQuery instance '%s': %v. Skipping initialization.", name, err)
@@ -134,13 +175,16 @@ func (r *Registry) initSlack(ctx context.Context, connectors config.SlackConnect
 
 	for name, slackCfg := range connectors.Instances {
 		// Validate required fields
-		if slackCfg.WebhookURL == "" {
-			logger.Warnf("Missing webhookURL for Slack instance '%s'. Skipping initialization.", name)
+		if slackCfg.WebhookURL == "" && (slackCfg.BotToken == "" || slackCfg.Channel == "") {
+			logger.Warnf("Missing webhookURL or botToken and channel for Slack instance '%s'. Skipping initialization.", name)
 			continue
 		}
 
 		client, err := slack.New(ctx, slack.Config{
-			WebhookURL: slackCfg.WebhookURL,
+			WebhookURL:     slackCfg.WebhookURL,
+			BotToken:       slackCfg.BotToken,
+			Channel:        slackCfg.Channel,
+			ThreadLookback: slackCfg.ThreadLookback,
 		})
 		if err != nil {
 			logger.Warnf("Error creating Slack instance '%s': %v. Skipping initialization.", name, err)
@@ -153,6 +197,211 @@ func (r *Registry) initSlack(ctx context.Context, connectors config.SlackConnect
 	}
 }
 
+func (r *Registry) initSyslog(ctx context.Context, connectors config.SyslogConnectors) {
+	if len(connectors.Instances) == 0 {
+		logger.Debug("No Syslog instances configured. Skipping Syslog initialization.")
+		return
+	}
+
+	for name, syslogCfg := range connectors.Instances {
+		// Validate required fields
+		if syslogCfg.Network == "" || syslogCfg.Address == "" || syslogCfg.Format == "" {
+			logger.Warnf("Missing required fields for Syslog instance '%s'. Skipping initialization.", name)
+			continue
+		}
+
+		client, err := syslog.New(ctx, syslog.Config{
+			Network:            syslogCfg.Network,
+			Address:            syslogCfg.Address,
+			Format:             syslogCfg.Format,
+			Facility:           syslogCfg.Facility,
+			AppName:            syslogCfg.AppName,
+			InsecureSkipVerify: syslogCfg.InsecureSkipVerify,
+		})
+		if err != nil {
+			logger.Warnf("Error creating Syslog instance '%s': %v. Skipping initialization.", name, err)
+			continue
+		}
+
+		instanceName := "syslog." + name
+		r.publishers[instanceName] = client
+		logger.Infof("Initialized Syslog instance '%s' as Publisher.", name)
+	}
+}
+
+func (r *Registry) initTeams(ctx context.Context, connectors config.TeamsConnectors) {
+	if len(connectors.Instances) == 0 {
+		logger.Debug("No Teams instances configured. Skipping Teams initialization.")
+		return
+	}
+
+	for name, teamsCfg := range connectors.Instances {
+		// Validate required fields
+		if teamsCfg.WebhookURL == "" {
+			logger.Warnf("Missing webhookURL for Teams instance '%s'. Skipping initialization.", name)
+			continue
+		}
+
+		client, err := teams.New(ctx, teams.Config{
+			WebhookURL: teamsCfg.WebhookURL,
+		})
+		if err != nil {
+			logger.Warnf("Error creating Teams instance '%s': %v. Skipping initialization.", name, err)
+			continue
+		}
+
+		instanceName := "teams." + name
+		r.publishers[instanceName] = client
+		logger.Infof("Initialized Teams instance '%s' as Publisher.", name)
+	}
+}
+
+func (r *Registry) initGChat(ctx context.Context, connectors config.GChatConnectors) {
+	if len(connectors.Instances) == 0 {
+		logger.Debug("No Google Chat instances configured. Skipping Google Chat initialization.")
+		return
+	}
+
+	for name, gchatCfg := range connectors.Instances {
+		// Validate required fields
+		if gchatCfg.WebhookURL == "" {
+			logger.Warnf("Missing webhookURL for Google Chat instance '%s'. Skipping initialization.", name)
+			continue
+		}
+
+		client, err := gchat.New(ctx, gchat.Config{
+			WebhookURL: gchatCfg.WebhookURL,
+		})
+		if err != nil {
+			logger.Warnf("Error creating Google Chat instance '%s': %v. Skipping initialization.", name, err)
+			continue
+		}
+
+		instanceName := "gchat." + name
+		r.publishers[instanceName] = client
+		logger.Infof("Initialized Google Chat instance '%s' as Publisher.", name)
+	}
+}
+
+func (r *Registry) initFile(ctx context.Context, connectors config.FileConnectors) {
+	if len(connectors.Instances) == 0 {
+		logger.Debug("No File instances configured. Skipping File initialization.")
+		return
+	}
+
+	for name, fileCfg := range connectors.Instances {
+		// Validate required fields
+		if fileCfg.Path == "" || fileCfg.Format == "" {
+			logger.Warnf("Missing required fields for File instance '%s'. Skipping initialization.", name)
+			continue
+		}
+
+		client, err := file.New(ctx, file.Config{
+			Path:           fileCfg.Path,
+			Format:         fileCfg.Format,
+			Gzip:           fileCfg.Gzip,
+			MaxSize:        fileCfg.MaxSize,
+			RotateInterval: fileCfg.RotateInterval,
+		})
+		if err != nil {
+			logger.Warnf("Error creating File instance '%s': %v. Skipping initialization.", name, err)
+			continue
+		}
+
+		instanceName := "file." + name
+		r.publishers[instanceName] = client
+		logger.Infof("Initialized File instance '%s' as Publisher.", name)
+	}
+}
+
+// initStdout registers the stdout publisher, which needs no configuration and defaults to JSONL.
+func (r *Registry) initStdout(ctx context.Context, stdoutCfg config.StdoutConfig) {
+	format := stdoutCfg.Format
+	if format == "" {
+		format = file.FormatJSONL
+	}
+
+	client, err := file.New(ctx, file.Config{
+		Format: format,
+		Writer: os.Stdout,
+	})
+	if err != nil {
+		logger.Warnf("Error creating stdout publisher: %v. Skipping initialization.", err)
+		return
+	}
+
+	r.publishers["stdout"] = client
+	logger.Debugf("Initialized stdout as Publisher.")
+}
+
+func (r *Registry) initObjectStore(ctx context.Context, connectors config.ObjectStoreConnectors) {
+	if len(connectors.Instances) == 0 {
+		logger.Debug("No ObjectStore instances configured. Skipping ObjectStore initialization.")
+		return
+	}
+
+	for name, osCfg := range connectors.Instances {
+		// Validate required fields
+		if osCfg.Provider == "" || osCfg.Bucket == "" || (osCfg.Provider == objectstore.ProviderS3 && osCfg.Endpoint == "") {
+			logger.Warnf("Missing required fields for ObjectStore instance '%s'. Skipping initialization.", name)
+			continue
+		}
+
+		client, err := objectstore.New(ctx, objectstore.Config{
+			Provider:        osCfg.Provider,
+			Bucket:          osCfg.Bucket,
+			Prefix:          osCfg.Prefix,
+			Endpoint:        osCfg.Endpoint,
+			Region:          osCfg.Region,
+			AccessKeyID:     osCfg.AccessKeyID,
+			SecretAccessKey: osCfg.SecretAccessKey,
+			DisableTLS:      osCfg.DisableTLS,
+			Encryption:      osCfg.Encryption,
+			KMSKeyID:        osCfg.KMSKeyID,
+		})
+		if err != nil {
+			logger.Warnf("Error creating ObjectStore instance '%s': %v. Skipping initialization.", name, err)
+			continue
+		}
+
+		instanceName := "objectstore." + name
+		r.publishers[instanceName] = client
+		logger.Infof("Initialized ObjectStore instance '%s' as Publisher.", name)
+	}
+}
+
+func (r *Registry) initTheHive(ctx context.Context, connectors config.TheHiveConnectors) {
+	if len(connectors.Instances) == 0 {
+		logger.Debug("No TheHive instances configured. Skipping TheHive initialization.")
+		return
+	}
+
+	for name, thCfg := range connectors.Instances {
+		// Validate required fields
+		if thCfg.URL == "" || thCfg.APIKey == "" {
+			logger.Warnf("Missing required fields for TheHive instance '%s'. Skipping initialization.", name)
+			continue
+		}
+
+		client, err := thehive.New(ctx, thehive.Config{
+			URL:                thCfg.URL,
+			APIKey:             thCfg.APIKey,
+			Organisation:       thCfg.Organisation,
+			Source:             thCfg.Source,
+			AlertType:          thCfg.AlertType,
+			InsecureSkipVerify: thCfg.InsecureSkipVerify,
+		})
+		if err != nil {
+			logger.Warnf("Error creating TheHive instance '%s': %v. Skipping initialization.", name, err)
+			continue
+		}
+
+		instanceName := "thehive." + name
+		r.publishers[instanceName] = client
+		logger.Infof("Initialized TheHive instance '%s' as Publisher.", name)
+	}
+}
+
 func (r *Registry) GetQueryRunner(name string) (QueryRunner, error) {
 	qr, exists := r.queryRunners[name]
 	if !exists {
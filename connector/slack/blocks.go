@@ -0,0 +1,234 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nianticlabs/venator/connector/internal/chat"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+	"golang.org/x/exp/maps"
+)
+
+// Slack limits, see https://api.slack.com/reference/block-kit/blocks.
+const (
+	maxBlocksPerMessage = 50
+	maxSectionText      = 3000
+	maxFieldText        = 2000
+	maxHeaderText       = 150
+	// maxMessageBytes bounds the encoded blocks of a message, keeping it well below the size at
+	// which Slack rejects or truncates messages.
+	maxMessageBytes = 40000
+)
+
+var confidenceColors = map[config.ConfidenceLevel]string{
+	config.ConfidenceHigh:   "#E01E5A",
+	config.ConfidenceMedium: "#ECB22E",
+	config.ConfidenceLow:    "#36C5F0",
+}
+
+const defaultColor = "#808080"
+
+// Field is a single key/value pair of a finding.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// TemplateData is passed to rule-level Block Kit templates.
+type TemplateData struct {
+	Rule    *config.RuleConfig
+	Index   int               // 1-based position of the finding
+	Row     result.Row        // typed finding values
+	Finding map[string]string // finding values flattened into dotted keys
+	Fields  []Field           // flattened finding fields sorted by key
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"truncate": chat.Truncate,
+}
+
+// findingRenderer renders the Block Kit blocks of a single finding.
+type findingRenderer func(index int, finding result.Row) ([]map[string]any, error)
+
+// newFindingRenderer returns the rule's custom template renderer if one is configured,
+// or the default layout listing every field in key order.
+func newFindingRenderer(cfg *config.RuleConfig) (findingRenderer, error) {
+	if cfg.Slack == nil || cfg.Slack.Template == "" {
+		return func(index int, finding result.Row) ([]map[string]any, error) {
+			return defaultFindingBlocks(index, finding), nil
+		}, nil
+	}
+
+	tmpl, err := template.New("slack").Funcs(templateFuncs).Parse(cfg.Slack.Template)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing slack template: %w", err)
+	}
+
+	return func(index int, finding result.Row) ([]map[string]any, error) {
+		var buf bytes.Buffer
+		flat := finding.Flatten()
+		data := TemplateData{
+			Rule:    cfg,
+			Index:   index,
+			Row:     finding,
+			Finding: flat,
+			Fields:  sortedFields(flat),
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("error executing slack template: %w", err)
+		}
+
+		var blocks []map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &blocks); err != nil {
+			return nil, fmt.Errorf("slack template did not render a JSON array of blocks: %w", err)
+		}
+		return blocks, nil
+	}, nil
+}
+
+func defaultFindingBlocks(index int, finding result.Row) []map[string]any {
+	var lines []string
+	for _, f := range sortedFields(finding.Flatten()) {
+		lines = append(lines, fmt.Sprintf("*%s*: %s", f.Key, f.Value))
+	}
+
+	return []map[string]any{
+		markdownSection(fmt.Sprintf("*Finding %d*\n%s", index, strings.Join(lines, "\n"))),
+	}
+}
+
+// summaryBlocks describes the rule: name, finding count, highest confidence and severity of the
+// findings, description and TTP links.
+func summaryBlocks(cfg *config.RuleConfig, results []result.Row) []map[string]any {
+	levels := fmt.Sprintf("Confidence: *%s*", signal.HighestConfidence(results, cfg))
+	if severity := signal.HighestSeverity(results, cfg); severity != "" {
+		levels += fmt.Sprintf(", severity: *%s*", severity)
+	}
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": chat.Truncate(cfg.Name, maxHeaderText)},
+		},
+		markdownSection(fmt.Sprintf("%d finding(s) generated by `%s` rule. %s", len(results), cfg.Name, levels)),
+	}
+	if cfg.Description != "" {
+		blocks = append(blocks, markdownSection(cfg.Description))
+	}
+
+	var ttps []map[string]any
+	for _, ttp := range cfg.TTPs {
+		text := fmt.Sprintf("%s %s: %s", ttp.Framework, ttp.ID, ttp.Name)
+		if ttp.Reference != "" {
+			text = fmt.Sprintf("<%s|%s>", ttp.Reference, text)
+		}
+		ttps = append(ttps, map[string]any{"type": "mrkdwn", "text": text})
+	}
+	if len(ttps) > 0 {
+		// Context blocks hold at most 10 elements.
+		if len(ttps) > 10 {
+			ttps = ttps[:10]
+		}
+		blocks = append(blocks, map[string]any{"type": "context", "elements": ttps})
+	}
+	return blocks
+}
+
+func markdownSection(text string) map[string]any {
+	return map[string]any{
+		"type": "section",
+		"text": map[string]any{"type": "mrkdwn", "text": chat.Truncate(text, maxSectionText)},
+	}
+}
+
+// capBlocks truncates the texts of section and header blocks, e.g. rendered by rule templates,
+// to the Slack limits.
+func capBlocks(blocks []map[string]any) []map[string]any {
+	for _, block := range blocks {
+		limit := maxSectionText
+		if block["type"] == "header" {
+			limit = maxHeaderText
+		}
+		if text, ok := block["text"].(map[string]any); ok {
+			if s, ok := text["text"].(string); ok {
+				text["text"] = chat.Truncate(s, limit)
+			}
+		}
+		if fields, ok := block["fields"].([]any); ok {
+			for _, field := range fields {
+				if field, ok := field.(map[string]any); ok {
+					if s, ok := field["text"].(string); ok {
+						field["text"] = chat.Truncate(s, maxFieldText)
+					}
+				}
+			}
+		}
+	}
+	return blocks
+}
+
+// chunkFindings groups the finding attachments into messages of at most limit blocks and maxBytes
+// encoded bytes each. A finding's blocks are never split across messages; the trailing blocks of
+// a finding over the limits are dropped.
+func chunkFindings(findings []attachment, limit, maxBytes int) [][]attachment {
+	var chunks [][]attachment
+	var current []attachment
+	currentBlocks, currentBytes := 0, 0
+	for _, finding := range findings {
+		if len(finding.Blocks) > limit {
+			finding.Blocks = finding.Blocks[:limit]
+		}
+		size := jsonSize(finding)
+		for len(finding.Blocks) > 1 && size > maxBytes {
+			finding.Blocks = finding.Blocks[:len(finding.Blocks)-1]
+			size = jsonSize(finding)
+		}
+		if len(current) > 0 && (currentBlocks+len(finding.Blocks) > limit || currentBytes+size > maxBytes) {
+			chunks = append(chunks, current)
+			current, currentBlocks, currentBytes = nil, 0, 0
+		}
+		current = append(current, finding)
+		currentBlocks += len(finding.Blocks)
+		currentBytes += size
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// jsonSize returns the size of v encoded as JSON.
+func jsonSize(v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+func confidenceColor(confidence config.ConfidenceLevel) string {
+	if color, ok := confidenceColors[confidence]; ok {
+		return color
+	}
+	return defaultColor
+}
+
+func sortedFields(finding map[string]string) []Field {
+	keys := maps.Keys(finding)
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, Field{Key: key, Value: finding[key]})
+	}
+	return fields
+}
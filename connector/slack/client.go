@@ -6,60 +6,271 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
 )
 
 type Client struct {
 	WebhookURL string
+
+	botToken       string
+	channel        string
+	threadLookback time.Duration
+	apiURL         string
+}
+
+const (
+	defaultAPIURL = "https://slack.com/api"
+	// parentEventType marks parent messages in their metadata so later runs of the same rule can find them.
+	parentEventType = "venator_rule"
+	historyLimit    = 200
+)
+
+type message struct {
+	Channel     string           `json:"channel,omitempty"`
+	ThreadTS    string           `json:"thread_ts,omitempty"`
+	Text        string           `json:"text"`
+	Blocks      []map[string]any `json:"blocks,omitempty"`
+	Attachments []attachment     `json:"attachments,omitempty"`
+	Metadata    *metadata        `json:"metadata,omitempty"`
+	Username    string           `json:"username,omitempty"`
+	IconEmoji   string           `json:"icon_emoji,omitempty"`
+}
+
+type attachment struct {
+	Color  string           `json:"color"`
+	Blocks []map[string]any `json:"blocks"`
+}
+
+type metadata struct {
+	EventType    string         `json:"event_type"`
+	EventPayload map[string]any `json:"event_payload"`
+}
+
+type apiResponse struct {
+	OK       bool   `json:"ok"`
+	Error    string `json:"error"`
+	TS       string `json:"ts"`
+	Messages []struct {
+		TS       string   `json:"ts"`
+		Metadata metadata `json:"metadata"`
+	} `json:"messages"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
 }
 
 func New(ctx context.Context, config Config) (*Client, error) {
+	if config.WebhookURL == "" && (config.BotToken == "" || config.Channel == "") {
+		return nil, fmt.Errorf("either a webhook URL or a bot token and channel are required")
+	}
+
 	return &Client{
-		WebhookURL: config.WebhookURL,
+		WebhookURL:     config.WebhookURL,
+		botToken:       config.BotToken,
+		channel:        config.Channel,
+		threadLookback: config.ThreadLookback,
+		apiURL:         defaultAPIURL,
 	}, nil
 }
 
-func (c *Client) Publish(ctx context.Context, results []map[string]string, cfg *config.RuleConfig) error {
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
 	if len(results) == 0 {
 		return nil
 	}
 
-	var attachments []map[string]interface{}
+	render, err := newFindingRenderer(cfg)
+	if err != nil {
+		return err
+	}
 
+	// Every finding is an attachment colored by its own confidence.
+	var findings []attachment
 	for i, r := range results {
-		var fields []map[string]interface{}
-
-		for key, value := range r {
-			fields = append(fields, map[string]interface{}{
-				"title": key,
-				"value": value,
-				"short": true,
-			})
+		blocks, err := render(i+1, r)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, attachment{
+			Color:  confidenceColor(signal.FindingConfidence(r, cfg)),
+			Blocks: capBlocks(blocks),
+		})
+	}
+
+	summary := summaryBlocks(cfg, results)
+	text := fmt.Sprintf("%d finding(s) generated by `%s` rule.", len(results), cfg.Name)
+
+	if c.botToken != "" {
+		return c.publishThreaded(ctx, cfg, summary, findings, text)
+	}
+	return c.publishWebhook(ctx, summary, findings, text)
+}
+
+// publishWebhook sends the summary with the first findings, then the remaining findings in follow-up messages.
+func (c *Client) publishWebhook(ctx context.Context, summary []map[string]any, findings []attachment, text string) error {
+	chunks := chunkFindings(findings, maxBlocksPerMessage-len(summary), maxMessageBytes-jsonSize(summary))
+	for i, chunk := range chunks {
+		msg := message{
+			Text:        text,
+			Attachments: chunk,
+			Username:    "venator",
+			IconEmoji:   ":bow_and_arrow:",
+		}
+		if i == 0 {
+			msg.Blocks = summary
+		} else {
+			msg.Text = fmt.Sprintf("%s (continued %d/%d)", text, i+1, len(chunks))
+		}
+
+		if err := c.postWebhook(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishThreaded posts findings as replies to a parent message for the rule. The parent from a previous
+// run is reused when it is within the lookback window, otherwise a new parent message is created.
+func (c *Client) publishThreaded(ctx context.Context, cfg *config.RuleConfig, summary []map[string]any, findings []attachment, text string) error {
+	parentTS, err := c.findParent(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if parentTS == "" {
+		resp, err := c.postMessage(ctx, message{
+			Channel: c.channel,
+			Text:    text,
+			Blocks:  summary,
+			Metadata: &metadata{
+				EventType:    parentEventType,
+				EventPayload: map[string]any{"rule_uid": cfg.UID},
+			},
+		})
+		if err != nil {
+			return err
 		}
+		parentTS = resp.TS
+	} else if _, err := c.postMessage(ctx, message{
+		Channel:  c.channel,
+		ThreadTS: parentTS,
+		Text:     text,
+		Blocks:   summary,
+	}); err != nil {
+		return err
+	}
 
-		attachment := map[string]interface{}{
-			"fallback": fmt.Sprintf("Finding %d", i+1),
-			"title":    fmt.Sprintf("Finding %d", i+1),
-			"fields":   fields,
-			"color":    "danger",
+	for _, chunk := range chunkFindings(findings, maxBlocksPerMessage, maxMessageBytes) {
+		if _, err := c.postMessage(ctx, message{
+			Channel:     c.channel,
+			ThreadTS:    parentTS,
+			Text:        text,
+			Attachments: chunk,
+		}); err != nil {
+			return err
 		}
-		attachments = append(attachments, attachment)
 	}
+	return nil
+}
 
-	payload := map[string]interface{}{
-		"text":        fmt.Sprintf("%d finding(s) generated by `%s` rule.", len(results), cfg.Name),
-		"attachments": attachments,
-		"username":    "venator",
-		"icon_emoji":  ":bow_and_arrow:",
+// findParent returns the timestamp of the most recent parent message of the rule within the lookback
+// window, or an empty string if there is none. Channel history is read page by page until it is found.
+func (c *Client) findParent(ctx context.Context, cfg *config.RuleConfig) (string, error) {
+	if c.threadLookback == 0 || cfg.UID == "" {
+		return "", nil
 	}
 
-	payloadBytes, err := json.Marshal(payload)
+	oldest := time.Now().Add(-c.threadLookback)
+	params := url.Values{
+		"channel":              {c.channel},
+		"oldest":               {strconv.FormatInt(oldest.Unix(), 10)},
+		"limit":                {strconv.Itoa(historyLimit)},
+		"include_all_metadata": {"true"},
+	}
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/conversations.history?"+params.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := c.callAPI(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to read slack channel history: %w", err)
+		}
+
+		// Messages are returned newest first.
+		for _, msg := range resp.Messages {
+			if msg.Metadata.EventType == parentEventType && msg.Metadata.EventPayload["rule_uid"] == cfg.UID {
+				return msg.TS, nil
+			}
+		}
+		if resp.ResponseMetadata.NextCursor == "" {
+			return "", nil
+		}
+		params.Set("cursor", resp.ResponseMetadata.NextCursor)
+	}
+}
+
+func (c *Client) postMessage(ctx context.Context, msg message) (*apiResponse, error) {
+	payloadBytes, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/chat.postMessage", bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.callAPI(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message to slack: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *Client) callAPI(req *http.Request) (*apiResponse, error) {
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %v", resp.StatusCode)
+	}
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("error decoding slack API response: %w", err)
+	}
+	if !apiResp.OK {
+		return nil, fmt.Errorf("slack API error: %s", apiResp.Error)
+	}
+	return &apiResp, nil
+}
+
+func (c *Client) postWebhook(ctx context.Context, msg message) error {
+	payloadBytes, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := http.Post(c.WebhookURL, "application/json", bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send message to slack: %w", err)
 	}
@@ -0,0 +1,288 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+var testRuleConfig = &config.RuleConfig{
+	Name:        "test-rule",
+	UID:         "test-uid",
+	Confidence:  config.ConfidenceHigh,
+	Description: "this is a test rule.",
+	TTPs: []config.TTP{
+		{Framework: "MITRE ATT&CK", Name: "Valid Accounts", ID: "T1078", Reference: "https://attack.mitre.org/techniques/T1078/"},
+	},
+}
+
+func TestDefaultFindingBlocksOrdering(t *testing.T) {
+	blocks := defaultFindingBlocks(1, result.Row{"zeta": int64(3), "alpha": "1", "mid": map[string]any{"value": "2"}})
+
+	text := blocks[0]["text"].(map[string]any)["text"]
+	expected := "*Finding 1*\n*alpha*: 1\n*mid.value*: 2\n*zeta*: 3"
+	if diff := cmp.Diff(expected, text); diff != "" {
+		t.Errorf("unexpected finding text (-want +got):\n%s", diff)
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	cfg := *testRuleConfig
+	cfg.Slack = &config.Slack{
+		Template: `[{"type": "section", "text": {"type": "mrkdwn", "text": {{ json (printf "#%d %s from %s" .Index .Rule.Name .Finding.user) }}}}]`,
+	}
+
+	render, err := newFindingRenderer(&cfg)
+	if err != nil {
+		t.Fatalf("newFindingRenderer() unexpected error: %v", err)
+	}
+	blocks, err := render(2, result.Row{"user": `al"ice`})
+	if err != nil {
+		t.Fatalf("render() unexpected error: %v", err)
+	}
+
+	text := blocks[0]["text"].(map[string]any)["text"]
+	if diff := cmp.Diff(`#2 test-rule from al"ice`, text); diff != "" {
+		t.Errorf("unexpected rendered text (-want +got):\n%s", diff)
+	}
+
+	cfg.Slack.Template = `not json`
+	render, _ = newFindingRenderer(&cfg)
+	if _, err := render(1, result.Row{}); err == nil {
+		t.Errorf("expected an error for a template that does not render JSON")
+	}
+}
+
+func TestPublishWebhookSplitsMessages(t *testing.T) {
+	var messages []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		messages = append(messages, msg)
+	}))
+	defer server.Close()
+
+	client, err := New(context.Background(), Config{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	var results []result.Row
+	for i := 0; i < 120; i++ {
+		results = append(results, result.Row{"user": fmt.Sprintf("user%d", i)})
+	}
+	if err := client.Publish(context.Background(), results, testRuleConfig); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	findings := 0
+	for i, msg := range messages {
+		blocks := len(msg.Blocks)
+		for _, a := range msg.Attachments {
+			if a.Color != confidenceColors[config.ConfidenceHigh] {
+				t.Errorf("message %d has unexpected color %q", i, a.Color)
+			}
+			blocks += len(a.Blocks)
+		}
+		if blocks > maxBlocksPerMessage {
+			t.Errorf("message %d has %d blocks, over the limit", i, blocks)
+		}
+		findings += len(msg.Attachments)
+	}
+	if findings != len(results) {
+		t.Errorf("expected %d findings across messages, got %d", len(results), findings)
+	}
+	if len(messages[0].Blocks) == 0 || len(messages[1].Blocks) != 0 {
+		t.Errorf("expected the summary only in the first message")
+	}
+}
+
+func TestPublishThreaded(t *testing.T) {
+	tests := []struct {
+		name string
+		// history holds the conversations.history responses by cursor.
+		history       map[string]string
+		wantParent    bool
+		wantThreadTS  string
+		wantPostCount int
+	}{
+		{
+			name:          "new parent message",
+			history:       map[string]string{"": `{"ok": true, "messages": []}`},
+			wantParent:    true,
+			wantThreadTS:  "111.000",
+			wantPostCount: 2,
+		},
+		{
+			name: "reuse parent from a previous run",
+			history: map[string]string{"": `{"ok": true, "messages": [
+				{"ts": "999.000", "metadata": {"event_type": "venator_rule", "event_payload": {"rule_uid": "other-uid"}}},
+				{"ts": "555.000", "metadata": {"event_type": "venator_rule", "event_payload": {"rule_uid": "test-uid"}}}
+			]}`},
+			wantThreadTS:  "555.000",
+			wantPostCount: 2,
+		},
+		{
+			name: "reuse parent from a later history page",
+			history: map[string]string{
+				"": `{"ok": true, "messages": [
+					{"ts": "999.000", "metadata": {"event_type": "venator_rule", "event_payload": {"rule_uid": "other-uid"}}}
+				], "response_metadata": {"next_cursor": "page2"}}`,
+				"page2": `{"ok": true, "messages": [
+					{"ts": "444.000", "metadata": {"event_type": "venator_rule", "event_payload": {"rule_uid": "test-uid"}}}
+				], "response_metadata": {"next_cursor": ""}}`,
+			},
+			wantThreadTS:  "444.000",
+			wantPostCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var posted []message
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+					t.Errorf("missing bot token")
+				}
+				switch r.URL.Path {
+				case "/conversations.history":
+					history, ok := tt.history[r.URL.Query().Get("cursor")]
+					if !ok {
+						t.Errorf("unexpected history cursor %q", r.URL.Query().Get("cursor"))
+					}
+					fmt.Fprint(w, history)
+				case "/chat.postMessage":
+					var msg message
+					if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+						t.Errorf("failed to decode message: %v", err)
+					}
+					mu.Lock()
+					posted = append(posted, msg)
+					mu.Unlock()
+					fmt.Fprint(w, `{"ok": true, "ts": "111.000"}`)
+				default:
+					t.Errorf("unexpected API call %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			client, err := New(context.Background(), Config{BotToken: "xoxb-test", Channel: "C1", ThreadLookback: time.Hour})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			client.apiURL = server.URL
+
+			results := []result.Row{{"user": "alice"}, {"user": "bob"}}
+			if err := client.Publish(context.Background(), results, testRuleConfig); err != nil {
+				t.Fatalf("Publish() unexpected error: %v", err)
+			}
+
+			if len(posted) != tt.wantPostCount {
+				t.Fatalf("expected %d posted messages, got %d", tt.wantPostCount, len(posted))
+			}
+			if isParent := posted[0].ThreadTS == "" && posted[0].Metadata != nil; isParent != tt.wantParent {
+				t.Errorf("expected new parent message to be %v", tt.wantParent)
+			}
+			last := posted[len(posted)-1]
+			if last.ThreadTS != tt.wantThreadTS {
+				t.Errorf("expected findings in thread %q, got %q", tt.wantThreadTS, last.ThreadTS)
+			}
+			if !strings.Contains(last.Text, "test-rule") {
+				t.Errorf("expected fallback text to mention the rule: %q", last.Text)
+			}
+		})
+	}
+}
+
+func TestCapBlocks(t *testing.T) {
+	blocks := capBlocks([]map[string]any{
+		{"type": "header", "text": map[string]any{"type": "plain_text", "text": strings.Repeat("h", 200)}},
+		{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": strings.Repeat("s", 5000)}},
+		{"type": "section", "fields": []any{map[string]any{"type": "mrkdwn", "text": strings.Repeat("f", 2500)}}},
+	})
+
+	lengths := []int{
+		len([]rune(blocks[0]["text"].(map[string]any)["text"].(string))),
+		len([]rune(blocks[1]["text"].(map[string]any)["text"].(string))),
+		len([]rune(blocks[2]["fields"].([]any)[0].(map[string]any)["text"].(string))),
+	}
+	if diff := cmp.Diff([]int{maxHeaderText, maxSectionText, maxFieldText}, lengths); diff != "" {
+		t.Errorf("unexpected text lengths (-want +got):\n%s", diff)
+	}
+}
+
+func TestChunkBlocksBySize(t *testing.T) {
+	var findings []attachment
+	for i := 0; i < 30; i++ {
+		findings = append(findings, attachment{Color: defaultColor, Blocks: defaultFindingBlocks(i+1, result.Row{"message": strings.Repeat("x", 2900)})})
+	}
+
+	chunks := chunkFindings(findings, maxBlocksPerMessage, maxMessageBytes)
+	if len(chunks) < 3 {
+		t.Fatalf("expected the findings to be split by size, got %d message(s)", len(chunks))
+	}
+	total := 0
+	for i, chunk := range chunks {
+		if size := jsonSize(chunk); size > maxMessageBytes {
+			t.Errorf("message %d has %d bytes, over the limit", i, size)
+		}
+		total += len(chunk)
+	}
+	if total != len(findings) {
+		t.Errorf("expected %d findings across messages, got %d", len(findings), total)
+	}
+}
+
+func TestPublishFindingColors(t *testing.T) {
+	var messages []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		messages = append(messages, msg)
+	}))
+	defer server.Close()
+
+	client, err := New(context.Background(), Config{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	cfg := *testRuleConfig
+	cfg.Confidence = config.ConfidenceLow
+	results := []result.Row{
+		{"user": "alice"},
+		{"user": "bob", "venator": map[string]any{"confidence": "high", "severity": "critical"}},
+	}
+	if err := client.Publish(context.Background(), results, &cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	if len(messages) != 1 || len(messages[0].Attachments) != 2 {
+		t.Fatalf("expected one message with an attachment per finding, got %+v", messages)
+	}
+	colors := []string{messages[0].Attachments[0].Color, messages[0].Attachments[1].Color}
+	if diff := cmp.Diff([]string{confidenceColors[config.ConfidenceLow], confidenceColors[config.ConfidenceHigh]}, colors); diff != "" {
+		t.Errorf("unexpected colors (-want +got):\n%s", diff)
+	}
+	summary, _ := json.Marshal(messages[0].Blocks)
+	if !strings.Contains(string(summary), "Confidence: *high*, severity: *critical*") {
+		t.Errorf("summary does not show the highest levels: %s", summary)
+	}
+}
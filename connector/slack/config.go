@@ -1,5 +1,14 @@
 package slack
 
+import "time"
+
 type Config struct {
 	WebhookURL string
+
+	// BotToken and Channel enable chat.postMessage mode, which threads findings under a parent message.
+	BotToken string
+	Channel  string
+	// ThreadLookback is how far back to look for an existing parent message of the same rule.
+	// When zero, a new parent message is posted on every run.
+	ThreadLookback time.Duration
 }
@@ -0,0 +1,180 @@
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+type Client struct {
+	network   string
+	address   string
+	format    string
+	facility  int
+	appName   string
+	hostname  string
+	tlsConfig *tls.Config
+}
+
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+
+	FormatCEF  = "cef"
+	FormatLEEF = "leef"
+
+	defaultAppName  = "venator"
+	defaultFacility = "local0"
+	dialTimeout     = 10 * time.Second
+)
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11, "ntp": 12, "security": 13, "console": 14, "solaris-cron": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+func New(ctx context.Context, config Config) (*Client, error) {
+	switch config.Network {
+	case NetworkUDP, NetworkTCP, NetworkTLS:
+	default:
+		return nil, fmt.Errorf("unsupported syslog network '%s'", config.Network)
+	}
+
+	switch config.Format {
+	case FormatCEF, FormatLEEF:
+	default:
+		return nil, fmt.Errorf("unsupported syslog payload format '%s'", config.Format)
+	}
+
+	facilityName := config.Facility
+	if facilityName == "" {
+		facilityName = defaultFacility
+	}
+	facility, ok := facilities[facilityName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported syslog facility '%s'", facilityName)
+	}
+
+	appName := config.AppName
+	if appName == "" {
+		appName = defaultAppName
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	c := &Client{
+		network:  config.Network,
+		address:  config.Address,
+		format:   config.Format,
+		facility: facility,
+		appName:  appName,
+		hostname: hostname,
+	}
+	if config.Network == NetworkTLS {
+		c.tlsConfig = &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify} // #nosec G402
+	}
+	return c, nil
+}
+
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var messages [][]byte
+	for _, r := range results {
+		sig, err := buildSignal(r, cfg)
+		if err != nil {
+			return err
+		}
+
+		var payload string
+		switch c.format {
+		case FormatCEF:
+			payload = encodeCEF(sig, cfg)
+		case FormatLEEF:
+			payload = encodeLEEF(sig, cfg)
+		}
+		messages = append(messages, c.buildMessage(payload, syslogSeverity(sig), time.Now()))
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog server: %w", err)
+	}
+	defer conn.Close()
+
+	for _, msg := range messages {
+		if err := c.write(conn, msg); err != nil {
+			return fmt.Errorf("failed to send syslog message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if c.network == NetworkTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: c.tlsConfig}
+		return tlsDialer.DialContext(ctx, "tcp", c.address)
+	}
+	return dialer.DialContext(ctx, c.network, c.address)
+}
+
+// write sends a single message. UDP carries one message per datagram, while stream transports
+// use the octet-counting framing from RFC 6587 / RFC 5425.
+func (c *Client) write(conn net.Conn, msg []byte) error {
+	if c.network == NetworkUDP {
+		_, err := conn.Write(msg)
+		return err
+	}
+	_, err := fmt.Fprintf(conn, "%d %s", len(msg), msg)
+	return err
+}
+
+// buildMessage formats an RFC 5424 message with no structured data.
+func (c *Client) buildMessage(payload string, severity int, ts time.Time) []byte {
+	pri := c.facility*8 + severity
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		pri, ts.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), c.hostname, c.appName, os.Getpid(), payload))
+}
+
+// syslogSeverity maps the finding severity, or its confidence if the rule sets no severity, to a
+// syslog severity level.
+func syslogSeverity(sig *signal.Signal) int {
+	switch sig.SeverityID {
+	case signal.SeverityCritical:
+		return 1 // alert
+	case signal.SeverityHigh:
+		return 2 // critical
+	case signal.SeverityMedium:
+		return 3 // error
+	case signal.SeverityLow:
+		return 4 // warning
+	case signal.SeverityInformational:
+		return 6 // informational
+	}
+	switch sig.ConfidenceID {
+	case signal.ConfidenceHigh:
+		return 2 // critical
+	case signal.ConfidenceMedium:
+		return 3 // error
+	case signal.ConfidenceLow:
+		return 4 // warning
+	default:
+		return 5 // notice
+	}
+}
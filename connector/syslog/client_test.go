@@ -0,0 +1,192 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+var testRuleConfig = &config.RuleConfig{
+	Name:       "test-rule",
+	UID:        "test-uid",
+	Confidence: config.ConfidenceHigh,
+	TTPs:       []config.TTP{{ID: "T1078"}, {ID: "T1110"}},
+	Output: config.Output{
+		Format: config.OutputFormatSignal,
+		Fields: []config.OutputField{
+			{Field: "Timestamp", Source: "timestamp"},
+			{Field: "ActorUserName", Source: "user"},
+			{Field: "SrcIP", Source: "ip"},
+			{Field: "Message", Source: "message"},
+		},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	row := result.Row{
+		"timestamp": "2023-05-14T10:00:00Z",
+		"user":      "alice",
+		"ip":        "10.0.0.1",
+		"message":   "login a=b\nfrom\tnew host",
+	}
+	sig, err := buildSignal(row, testRuleConfig)
+	if err != nil {
+		t.Fatalf("buildSignal() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		encode   func() string
+		expected string
+	}{
+		{
+			name:   "CEF",
+			encode: func() string { return encodeCEF(sig, testRuleConfig) },
+			expected: "CEF:0|Niantic|Venator|1.0|test-uid|test-rule|8|rt=1684058400000 suser=alice src=10.0.0.1 " +
+				"cs1Label=confidence cs1=high cs2Label=ttps cs2=T1078,T1110 msg=login a\\=b\\nfrom\tnew host",
+		},
+		{
+			name:   "LEEF",
+			encode: func() string { return encodeLEEF(sig, testRuleConfig) },
+			expected: "LEEF:1.0|Niantic|Venator|1.0|test-uid|cat=test-rule\tsev=8\tdevTime=1684058400000\tusrName=alice\t" +
+				"src=10.0.0.1\tconfidence=high\tttps=T1078,T1110\tmsg=login a=b from new host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.encode(); got != tt.expected {
+				t.Errorf("unexpected payload:\nwant %q\ngot  %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindingSeverity(t *testing.T) {
+	tests := []struct {
+		name                 string
+		row                  result.Row
+		cef, syslog          int
+		confidence, severity string
+	}{
+		{name: "rule confidence", row: result.Row{}, cef: 8, syslog: 2, confidence: "high"},
+		{name: "finding severity", row: result.Row{"venator.severity": "critical"}, cef: 10, syslog: 1, confidence: "high", severity: "critical"},
+		{name: "finding confidence", row: result.Row{"venator.confidence": "low"}, cef: 3, syslog: 4, confidence: "low"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := result.Row{"timestamp": "2023-05-14T10:00:00Z", "user": "alice", "ip": "10.0.0.1", "message": "login"}
+			for k, v := range tt.row {
+				row[k] = v
+			}
+			sig, err := buildSignal(row, testRuleConfig)
+			if err != nil {
+				t.Fatalf("buildSignal() unexpected error: %v", err)
+			}
+			if got := cefSeverity(sig); got != tt.cef {
+				t.Errorf("expected CEF severity %d, got %d", tt.cef, got)
+			}
+			if got := syslogSeverity(sig); got != tt.syslog {
+				t.Errorf("expected syslog severity %d, got %d", tt.syslog, got)
+			}
+			payload := encodeCEF(sig, testRuleConfig)
+			if !strings.Contains(payload, "cs1="+tt.confidence+" ") {
+				t.Errorf("expected confidence %s in %q", tt.confidence, payload)
+			}
+			if tt.severity != "" && !strings.Contains(payload, "cs4Label=severity cs4="+tt.severity+" ") {
+				t.Errorf("expected severity %s in %q", tt.severity, payload)
+			}
+		})
+	}
+}
+
+func TestPublishTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+
+		var msgs []string
+		reader := bufio.NewReader(conn)
+		for {
+			var length int
+			if _, err := fmt.Fscanf(reader, "%d ", &length); err != nil {
+				break
+			}
+			buf := make([]byte, length)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				break
+			}
+			msgs = append(msgs, string(buf))
+		}
+		received <- msgs
+	}()
+
+	client, err := New(context.Background(), Config{
+		Network: NetworkTCP,
+		Address: listener.Addr().String(),
+		Format:  FormatCEF,
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	results := []result.Row{
+		{"timestamp": "2023-05-14T10:00:00Z", "user": "alice", "ip": "10.0.0.1", "message": "first"},
+		{"timestamp": "2023-05-14T11:00:00Z", "user": "bob", "ip": "10.0.0.2", "message": "second"},
+	}
+	if err := client.Publish(context.Background(), results, testRuleConfig); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	msgs := <-received
+	if len(msgs) != len(results) {
+		t.Fatalf("expected %d messages, got %d", len(results), len(msgs))
+	}
+	for i, msg := range msgs {
+		// local0 (16) * 8 + critical (2)
+		if !strings.HasPrefix(msg, "<130>1 ") {
+			t.Errorf("message %d has unexpected header: %q", i, msg)
+		}
+		if !strings.Contains(msg, " venator ") || !strings.HasSuffix(msg, "msg="+results[i]["message"].(string)) {
+			t.Errorf("message %d has unexpected content: %q", i, msg)
+		}
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "unsupported network", cfg: Config{Network: "http", Address: "localhost:514", Format: FormatCEF}},
+		{name: "unsupported format", cfg: Config{Network: NetworkUDP, Address: "localhost:514", Format: "json"}},
+		{name: "unsupported facility", cfg: Config{Network: NetworkUDP, Address: "localhost:514", Format: FormatLEEF, Facility: "local9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(context.Background(), tt.cfg); err == nil {
+				t.Fatalf("expected an error but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package syslog
+
+type Config struct {
+	Network            string // udp, tcp or tls
+	Address            string
+	Format             string // cef or leef
+	Facility           string
+	AppName            string
+	InsecureSkipVerify bool
+}
@@ -0,0 +1,192 @@
+package syslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+const (
+	deviceVendor  = "Niantic"
+	deviceProduct = "Venator"
+	deviceVersion = "1.0"
+)
+
+// extension is a single key/value pair of a CEF or LEEF payload.
+type extension struct {
+	key   string
+	value string
+}
+
+// buildSignal maps a result to a signal. Rules with raw output have no field mapping,
+// so the rule context is kept and the whole result is carried as the message.
+func buildSignal(row result.Row, cfg *config.RuleConfig) (*signal.Signal, error) {
+	if cfg.Output.MapsFields() {
+		return signal.BuildSignal(row, cfg)
+	}
+
+	rawCfg := *cfg
+	rawCfg.Output.Fields = nil
+	sig, err := signal.BuildSignal(row, &rawCfg)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	sig.Message = string(raw)
+	return sig, nil
+}
+
+// buildExtensions returns the signal fields in a fixed order, keyed by their CEF names.
+// Empty values are omitted.
+func buildExtensions(sig *signal.Signal, cfg *config.RuleConfig) []extension {
+	ts := sig.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	var ttpIDs []string
+	for _, ttp := range cfg.TTPs {
+		ttpIDs = append(ttpIDs, ttp.ID)
+	}
+
+	var exts []extension
+	add := func(key, value string) {
+		if value != "" {
+			exts = append(exts, extension{key, value})
+		}
+	}
+	addCustom := func(n int, label, value string) {
+		if value != "" {
+			exts = append(exts, extension{fmt.Sprintf("cs%dLabel", n), label}, extension{fmt.Sprintf("cs%d", n), value})
+		}
+	}
+
+	add("rt", strconv.FormatInt(ts.UnixMilli(), 10))
+	add("suser", sig.Actor.User.Name)
+	add("suid", sig.Actor.User.UID)
+	add("shost", sig.SrcEndpoint.Hostname)
+	add("src", sig.SrcEndpoint.IP)
+	add("dhost", sig.DstEndpoint.Hostname)
+	add("dst", sig.DstEndpoint.IP)
+	add("externalId", sig.Metadata.EventID)
+	addCustom(1, "confidence", sig.Confidence)
+	addCustom(2, "ttps", strings.Join(ttpIDs, ","))
+	addCustom(3, "resource", sig.Resource.Name)
+	addCustom(4, "severity", sig.Severity)
+	add("msg", sig.Message)
+	return exts
+}
+
+// encodeCEF encodes the signal as an ArcSight Common Event Format payload.
+func encodeCEF(sig *signal.Signal, cfg *config.RuleConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscape(deviceVendor),
+		cefHeaderEscape(deviceProduct),
+		cefHeaderEscape(deviceVersion),
+		cefHeaderEscape(sig.Rule_ID),
+		cefHeaderEscape(sig.Rule_Name),
+		cefSeverity(sig))
+
+	for i, ext := range buildExtensions(sig, cfg) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(ext.key + "=" + cefExtensionEscape(ext.value))
+	}
+	return b.String()
+}
+
+// leefKeys maps CEF extension keys to their LEEF equivalents.
+var leefKeys = map[string]string{
+	"rt":         "devTime",
+	"suser":      "usrName",
+	"suid":       "accountId",
+	"shost":      "srcHostName",
+	"dhost":      "dstHostName",
+	"externalId": "eventId",
+	"cs1":        "confidence",
+	"cs2":        "ttps",
+	"cs3":        "resource",
+	"cs4":        "severity",
+}
+
+// encodeLEEF encodes the signal as an IBM QRadar LEEF 1.0 payload with tab-delimited attributes.
+func encodeLEEF(sig *signal.Signal, cfg *config.RuleConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "LEEF:1.0|%s|%s|%s|%s|",
+		leefHeaderEscape(deviceVendor),
+		leefHeaderEscape(deviceProduct),
+		leefHeaderEscape(deviceVersion),
+		leefHeaderEscape(sig.Rule_ID))
+
+	attrs := []extension{
+		{"cat", sig.Rule_Name},
+		{"sev", strconv.Itoa(cefSeverity(sig))},
+	}
+	for _, ext := range buildExtensions(sig, cfg) {
+		if strings.HasSuffix(ext.key, "Label") {
+			continue
+		}
+		if key, ok := leefKeys[ext.key]; ok {
+			ext.key = key
+		}
+		attrs = append(attrs, ext)
+	}
+
+	for i, attr := range attrs {
+		if i > 0 {
+			b.WriteByte('\t')
+		}
+		b.WriteString(attr.key + "=" + leefValueEscape(attr.value))
+	}
+	return b.String()
+}
+
+// cefSeverity maps the finding severity, or its confidence if the rule sets no severity, to the
+// 0-10 CEF/LEEF severity scale.
+func cefSeverity(sig *signal.Signal) int {
+	switch sig.SeverityID {
+	case signal.SeverityCritical:
+		return 10
+	case signal.SeverityHigh:
+		return 8
+	case signal.SeverityMedium:
+		return 6
+	case signal.SeverityLow:
+		return 3
+	case signal.SeverityInformational:
+		return 1
+	}
+	switch sig.ConfidenceID {
+	case signal.ConfidenceHigh:
+		return 8
+	case signal.ConfidenceMedium:
+		return 6
+	case signal.ConfidenceLow:
+		return 3
+	default:
+		return 0
+	}
+}
+
+var (
+	cefHeaderReplacer    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionReplacer = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefHeaderReplacer   = strings.NewReplacer(`|`, `\|`, "\n", " ", "\r", " ")
+	leefValueReplacer    = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func cefHeaderEscape(s string) string    { return cefHeaderReplacer.Replace(s) }
+func cefExtensionEscape(s string) string { return cefExtensionReplacer.Replace(s) }
+func leefHeaderEscape(s string) string   { return leefHeaderReplacer.Replace(s) }
+func leefValueEscape(s string) string    { return leefValueReplacer.Replace(s) }
@@ -0,0 +1,144 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/nianticlabs/venator/connector/internal/chat"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+	"golang.org/x/exp/maps"
+)
+
+type Client struct {
+	WebhookURL string
+}
+
+const (
+	// Teams rejects webhook payloads larger than ~28 KB.
+	maxPayloadBytes = 28000
+	maxValueLength  = 1000
+	cardContentType = "application/vnd.microsoft.card.adaptive"
+	cardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	cardVersion     = "1.4"
+)
+
+func New(ctx context.Context, config Config) (*Client, error) {
+	return &Client{
+		WebhookURL: config.WebhookURL,
+	}, nil
+}
+
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	// Keep as many findings as fit in the payload, noting the omission in the summary card.
+	payloadBytes, _, err := chat.Fit(len(results), maxPayloadBytes, func(n int) any {
+		return buildPayload(results, n, cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message to teams: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Incoming webhooks respond with 200, Workflows webhooks with 202.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code: %v", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// buildPayload builds a message with a summary card followed by one card for each of the first n findings.
+func buildPayload(results []result.Row, n int, cfg *config.RuleConfig) map[string]interface{} {
+	facts := []map[string]string{
+		{"title": "Rule", "value": cfg.Name},
+		{"title": "Confidence", "value": string(signal.HighestConfidence(results, cfg))},
+	}
+	if severity := signal.HighestSeverity(results, cfg); severity != "" {
+		facts = append(facts, map[string]string{"title": "Severity", "value": string(severity)})
+	}
+	summary := []map[string]interface{}{
+		{
+			"type":   "TextBlock",
+			"text":   fmt.Sprintf("%d finding(s) generated by `%s` rule.", len(results), cfg.Name),
+			"weight": "Bolder",
+			"size":   "Medium",
+			"wrap":   true,
+		},
+		{
+			"type":  "FactSet",
+			"facts": facts,
+		},
+	}
+	if n < len(results) {
+		summary = append(summary, map[string]interface{}{
+			"type":     "TextBlock",
+			"text":     fmt.Sprintf("%d finding(s) omitted due to message size limits.", len(results)-n),
+			"isSubtle": true,
+			"wrap":     true,
+		})
+	}
+
+	attachments := []map[string]interface{}{buildCard(summary)}
+	for i, r := range results[:n] {
+		flat := r.Flatten()
+		keys := maps.Keys(flat)
+		sort.Strings(keys)
+
+		var facts []map[string]string
+		for _, key := range keys {
+			facts = append(facts, map[string]string{
+				"title": key,
+				"value": chat.Truncate(flat[key], maxValueLength),
+			})
+		}
+
+		attachments = append(attachments, buildCard([]map[string]interface{}{
+			{
+				"type":   "TextBlock",
+				"text":   fmt.Sprintf("Finding %d (%s)", i+1, chat.Levels([]result.Row{r}, cfg)),
+				"weight": "Bolder",
+				"color":  "Attention",
+			},
+			{
+				"type":  "FactSet",
+				"facts": facts,
+			},
+		}))
+	}
+
+	return map[string]interface{}{
+		"type":        "message",
+		"attachments": attachments,
+	}
+}
+
+func buildCard(body []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"contentType": cardContentType,
+		"content": map[string]interface{}{
+			"$schema": cardSchema,
+			"type":    "AdaptiveCard",
+			"version": cardVersion,
+			"body":    body,
+		},
+	}
+}
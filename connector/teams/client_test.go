@@ -0,0 +1,111 @@
+package teams_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nianticlabs/venator/connector/internal/chat/chattest"
+	"github.com/nianticlabs/venator/connector/teams"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		name            string
+		results         []result.Row
+		wantAttachments int
+		wantOmitted     bool
+	}{
+		{
+			name: "all findings fit",
+			results: []result.Row{
+				{"user": "alice", "ip": "10.0.0.1"},
+				{"user": "bob", "ip": "10.0.0.2"},
+			},
+			wantAttachments: 3,
+		},
+		{
+			name:            "findings truncated over size limit",
+			results:         chattest.LargeResults(100),
+			wantAttachments: 0, // checked against the size limit below
+			wantOmitted:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			client, err := teams.New(context.Background(), teams.Config{WebhookURL: server.URL})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			cfg := &config.RuleConfig{Name: "test-rule", Confidence: config.ConfidenceHigh}
+			if err := client.Publish(context.Background(), tt.results, cfg); err != nil {
+				t.Fatalf("Publish() unexpected error: %v", err)
+			}
+
+			var payload struct {
+				Attachments []json.RawMessage `json:"attachments"`
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			if len(body) > 28000 {
+				t.Errorf("payload size %d exceeds limit", len(body))
+			}
+			if tt.wantAttachments > 0 && len(payload.Attachments) != tt.wantAttachments {
+				t.Errorf("expected %d attachments, got %d", tt.wantAttachments, len(payload.Attachments))
+			}
+			if omitted := strings.Contains(string(body), "omitted due to message size limits"); omitted != tt.wantOmitted {
+				t.Errorf("expected omitted note to be %v", tt.wantOmitted)
+			}
+			if !strings.Contains(string(payload.Attachments[0]), "test-rule") {
+				t.Errorf("summary card does not mention the rule name")
+			}
+		})
+	}
+}
+
+func TestPublishFindingLevels(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := teams.New(context.Background(), teams.Config{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	cfg := &config.RuleConfig{Name: "test-rule", Confidence: config.ConfidenceLow}
+	results := []result.Row{
+		{"user": "alice"},
+		{"user": "bob", "venator": map[string]any{"confidence": "high", "severity": "critical"}},
+	}
+	if err := client.Publish(context.Background(), results, cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		`{"title":"Confidence","value":"high"},{"title":"Severity","value":"critical"}`,
+		`"Finding 1 (confidence: low)"`,
+		`"Finding 2 (confidence: high, severity: critical)"`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected %q in payload %s", want, body)
+		}
+	}
+}
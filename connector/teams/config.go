@@ -0,0 +1,5 @@
+package teams
+
+type Config struct {
+	WebhookURL string
+}
@@ -0,0 +1,249 @@
+package thehive
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "connector/thehive")
+
+type Client struct {
+	url          string
+	apiKey       string
+	organisation string
+	source       string
+	alertType    string
+	httpClient   *http.Client
+}
+
+const (
+	alertPath        = "/api/v1/alert"
+	defaultSource    = "venator"
+	defaultAlertType = "venator"
+)
+
+// Alert is the subset of the TheHive alert creation payload set by Venator.
+type Alert struct {
+	Type        string       `json:"type"`
+	Source      string       `json:"source"`
+	SourceRef   string       `json:"sourceRef"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Severity    int          `json:"severity"`
+	Tags        []string     `json:"tags,omitempty"`
+	Observables []Observable `json:"observables,omitempty"`
+}
+
+type Observable struct {
+	DataType string   `json:"dataType"`
+	Data     string   `json:"data"`
+	Message  string   `json:"message,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func New(ctx context.Context, config Config) (*Client, error) {
+	source := config.Source
+	if source == "" {
+		source = defaultSource
+	}
+	alertType := config.AlertType
+	if alertType == "" {
+		alertType = defaultAlertType
+	}
+
+	return &Client{
+		url:          strings.TrimSuffix(config.URL, "/"),
+		apiKey:       config.APIKey,
+		organisation: config.Organisation,
+		source:       source,
+		alertType:    alertType,
+		httpClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}, // #nosec G402
+		}},
+	}, nil
+}
+
+func (c *Client) Publish(ctx context.Context, results []result.Row, cfg *config.RuleConfig) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var errArr []error
+	for _, r := range results {
+		alert, err := c.buildAlert(r, cfg)
+		if err != nil {
+			return err
+		}
+		if err := c.createAlert(ctx, alert); err != nil {
+			errArr = append(errArr, fmt.Errorf("alert %s: %w", alert.SourceRef, err))
+		}
+	}
+	return errors.Join(errArr...)
+}
+
+func (c *Client) buildAlert(row result.Row, cfg *config.RuleConfig) (*Alert, error) {
+	// Raw output has no field mapping, so its signal only carries the rule context and the
+	// confidence and severity of the finding, without observables.
+	sigCfg := cfg
+	if !cfg.Output.MapsFields() {
+		rawCfg := *cfg
+		rawCfg.Output.Fields = nil
+		sigCfg = &rawCfg
+	}
+	sig, err := signal.BuildSignal(row, sigCfg)
+	if err != nil {
+		return nil, err
+	}
+	// The finding UID only depends on the query results, so the same finding is raised once.
+	uid, err := signal.FindingUID(row, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := row.Flatten()
+	alert := &Alert{
+		Type:        c.alertType,
+		Source:      c.source,
+		SourceRef:   uid,
+		Title:       cfg.Name,
+		Description: buildDescription(flat, cfg),
+		Severity:    severity(sig),
+		Tags:        append([]string{}, cfg.Tags...),
+		Observables: extractObservables(sig),
+	}
+	for _, ttp := range cfg.TTPs {
+		if ttp.ID != "" {
+			alert.Tags = append(alert.Tags, ttp.ID)
+		}
+	}
+	return alert, nil
+}
+
+func (c *Client) createAlert(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+alertPath, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if c.organisation != "" {
+		req.Header.Set("X-Organisation", c.organisation)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert to thehive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	// TheHive rejects alerts with a type, source and sourceRef that already exist.
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(respBody), "already exists") {
+		logger.Debugf("alert %s already exists, skipping", alert.SourceRef)
+		return nil
+	}
+	return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+func buildDescription(fields map[string]string, cfg *config.RuleConfig) string {
+	var b strings.Builder
+	if cfg.Description != "" {
+		b.WriteString(cfg.Description + "\n\n")
+	}
+
+	b.WriteString("| Field | Value |\n|---|---|\n")
+	keys := maps.Keys(fields)
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "| %s | %s |\n", markdownEscape(k), markdownEscape(fields[k]))
+	}
+
+	if len(cfg.TTPs) > 0 {
+		b.WriteString("\n**TTPs**\n")
+		for _, ttp := range cfg.TTPs {
+			fmt.Fprintf(&b, "- [%s %s: %s](%s)\n", ttp.Framework, ttp.ID, ttp.Name, ttp.Reference)
+		}
+	}
+	if len(cfg.References) > 0 {
+		b.WriteString("\n**References**\n")
+		for _, ref := range cfg.References {
+			fmt.Fprintf(&b, "- %s\n", ref)
+		}
+	}
+	return b.String()
+}
+
+// extractObservables returns the IPs, hostnames and usernames of the signal, without duplicates.
+func extractObservables(sig *signal.Signal) []Observable {
+	var observables []Observable
+	seen := make(map[string]bool)
+	add := func(dataType, data, message string, tags ...string) {
+		if data == "" || seen[dataType+"|"+data] {
+			return
+		}
+		seen[dataType+"|"+data] = true
+		observables = append(observables, Observable{DataType: dataType, Data: data, Message: message, Tags: tags})
+	}
+
+	add("ip", sig.SrcEndpoint.IP, "source IP")
+	add("ip", sig.DstEndpoint.IP, "destination IP")
+	add("hostname", sig.SrcEndpoint.Hostname, "source hostname")
+	add("hostname", sig.DstEndpoint.Hostname, "destination hostname")
+	// TheHive has no built-in username type.
+	add("other", sig.Actor.User.Name, "actor username", "username")
+	add("other", sig.Actor.User.UID, "actor user UID", "user-uid")
+	return observables
+}
+
+// severity maps the finding severity, or its confidence if the rule sets no severity, to the
+// TheHive severity scale (1 low to 4 critical).
+func severity(sig *signal.Signal) int {
+	switch sig.SeverityID {
+	case signal.SeverityCritical:
+		return 4
+	case signal.SeverityHigh:
+		return 3
+	case signal.SeverityMedium:
+		return 2
+	case signal.SeverityLow, signal.SeverityInformational:
+		return 1
+	}
+	switch sig.ConfidenceID {
+	case signal.ConfidenceHigh:
+		return 3
+	case signal.ConfidenceLow:
+		return 1
+	default:
+		return 2
+	}
+}
+
+var markdownReplacer = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func markdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
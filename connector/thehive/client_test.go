@@ -0,0 +1,137 @@
+package thehive_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/connector/thehive"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func TestPublish(t *testing.T) {
+	var alerts []thehive.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/alert" || r.Header.Get("Authorization") != "Bearer test-key" || r.Header.Get("X-Organisation") != "secops" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		var alert thehive.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("failed to decode alert: %v", err)
+		}
+		// The second alert with the same sourceRef is a duplicate.
+		for _, a := range alerts {
+			if a.SourceRef == alert.SourceRef {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"type":"CreateError","message":"Alert already exists"}`))
+				return
+			}
+		}
+		alerts = append(alerts, alert)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := thehive.New(context.Background(), thehive.Config{URL: server.URL, APIKey: "test-key", Organisation: "secops"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	cfg := &config.RuleConfig{
+		Name:        "test-rule",
+		UID:         "test-uid",
+		Confidence:  config.ConfidenceHigh,
+		Description: "this is a test rule.",
+		Tags:        []string{"test"},
+		TTPs:        []config.TTP{{Framework: "MITRE ATT&CK", ID: "T1078", Name: "Valid Accounts"}},
+		Output: config.Output{
+			Format: config.OutputFormatSignal,
+			Fields: []config.OutputField{
+				{Field: "ActorUserName", Source: "user"},
+				{Field: "SrcIP", Source: "src_ip"},
+				{Field: "DstIP", Source: "dst_ip"},
+				{Field: "SrcHostname", Source: "host"},
+			},
+		},
+	}
+	// Fields added after the finding UIDs are set, such as scores, do not change the sourceRef.
+	results := []result.Row{
+		{"user": "alice", "src_ip": "10.0.0.1", "dst_ip": "10.0.0.1", "host": "laptop-1"},
+		{"user": "alice", "src_ip": "10.0.0.1", "dst_ip": "10.0.0.1", "host": "laptop-1"},
+	}
+	if err := signal.SetFindingUIDs(results, cfg); err != nil {
+		t.Fatalf("SetFindingUIDs() unexpected error: %v", err)
+	}
+	uid := results[0][signal.FindingUIDField]
+	results[1]["venator"] = map[string]any{"risk_score": int64(40)}
+
+	if err := client.Publish(context.Background(), results, cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert to be created, got %d", len(alerts))
+	}
+
+	alert := alerts[0]
+	if alert.SourceRef != uid || alert.Title != "test-rule" || alert.Severity != 3 {
+		t.Errorf("unexpected alert fields: %+v", alert)
+	}
+	if diff := cmp.Diff([]string{"test", "T1078"}, alert.Tags); diff != "" {
+		t.Errorf("unexpected tags (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(alert.Description, "| user | alice |") {
+		t.Errorf("description does not list the finding fields: %q", alert.Description)
+	}
+
+	expectedObservables := []thehive.Observable{
+		{DataType: "ip", Data: "10.0.0.1", Message: "source IP"},
+		{DataType: "hostname", Data: "laptop-1", Message: "source hostname"},
+		{DataType: "other", Data: "alice", Message: "actor username", Tags: []string{"username"}},
+	}
+	if diff := cmp.Diff(expectedObservables, alert.Observables); diff != "" {
+		t.Errorf("unexpected observables (-want +got):\n%s", diff)
+	}
+}
+
+func TestPublishFindingSeverity(t *testing.T) {
+	var severities []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert thehive.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("failed to decode alert: %v", err)
+		}
+		severities = append(severities, alert.Severity)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := thehive.New(context.Background(), thehive.Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// Scoring and intel matches set the severity and confidence of single findings.
+	cfg := &config.RuleConfig{
+		Name:       "test-rule",
+		UID:        "test-uid",
+		Confidence: config.ConfidenceMedium,
+		Output:     config.Output{Format: config.OutputFormatRaw},
+	}
+	results := []result.Row{
+		{"user": "alice"},
+		{"user": "bob", "venator": map[string]any{"severity": "critical"}},
+		{"user": "carol", "venator": map[string]any{"confidence": "high"}},
+	}
+	if err := client.Publish(context.Background(), results, cfg); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{2, 4, 3}, severities); diff != "" {
+		t.Errorf("unexpected severities (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,10 @@
+package thehive
+
+type Config struct {
+	URL                string
+	APIKey             string
+	Organisation       string // Optional
+	Source             string
+	AlertType          string
+	InsecureSkipVerify bool
+}
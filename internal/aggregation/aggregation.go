@@ -0,0 +1,258 @@
+// Package aggregation groups query results for query engines that cannot aggregate themselves.
+package aggregation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+var operators = map[string]func(a, b float64) bool{
+	"gt":         func(a, b float64) bool { return a > b },
+	"gte":        func(a, b float64) bool { return a >= b },
+	"lt":         func(a, b float64) bool { return a < b },
+	"lte":        func(a, b float64) bool { return a <= b },
+	"equals":     func(a, b float64) bool { return a == b },
+	"not_equals": func(a, b float64) bool { return a != b },
+}
+
+// Validate checks the aggregation config, so mistakes are reported when the rule is loaded.
+func Validate(cfg *config.Aggregation) error {
+	if len(cfg.Aggregates) == 0 {
+		return fmt.Errorf("aggregation requires at least one aggregate")
+	}
+	fields := make(map[string]bool)
+	for _, field := range cfg.GroupBy {
+		fields[field] = true
+	}
+	for i, agg := range cfg.Aggregates {
+		if agg.Field == "" {
+			return fmt.Errorf("aggregate %d: field is required", i+1)
+		}
+		if fields[agg.Field] {
+			return fmt.Errorf("aggregate %d: duplicate field %s", i+1, agg.Field)
+		}
+		fields[agg.Field] = true
+
+		switch agg.Function {
+		case config.AggregateCount:
+		case config.AggregateDistinctCount, config.AggregateMin, config.AggregateMax, config.AggregateSum, config.AggregateValues:
+			if agg.Source == "" {
+				return fmt.Errorf("aggregate %s: function %s requires a source", agg.Field, agg.Function)
+			}
+		default:
+			return fmt.Errorf("aggregate %s: unsupported function '%s'", agg.Field, agg.Function)
+		}
+		if agg.Limit < 0 || agg.Limit > 0 && agg.Function != config.AggregateValues {
+			return fmt.Errorf("aggregate %s: limit must be positive and only applies to values", agg.Field)
+		}
+	}
+	for i, h := range cfg.Having {
+		if !fields[h.Field] {
+			return fmt.Errorf("having %d: unknown field %s", i+1, h.Field)
+		}
+		if _, ok := operators[h.Operator]; !ok {
+			return fmt.Errorf("having %d: unsupported operator '%s'", i+1, h.Operator)
+		}
+	}
+	return nil
+}
+
+// Aggregate groups rows by the group-by fields and returns one row per group matching the having
+// conditions, in the order the groups were first seen. Missing and null values are ignored by every
+// function but count without a source.
+func Aggregate(rows []result.Row, cfg *config.Aggregation) ([]result.Row, error) {
+	var keys []string
+	groups := make(map[string][]result.Row)
+	for _, row := range rows {
+		key := groupKey(row, cfg.GroupBy)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	var out []result.Row
+	for _, key := range keys {
+		group := groups[key]
+		aggregated := make(result.Row)
+		for _, field := range cfg.GroupBy {
+			aggregated[field], _ = group[0].Get(field)
+		}
+		for _, agg := range cfg.Aggregates {
+			value, err := compute(agg, group)
+			if err != nil {
+				return nil, fmt.Errorf("aggregate %s: %w", agg.Field, err)
+			}
+			aggregated[agg.Field] = value
+		}
+
+		keep, err := having(aggregated, cfg.Having)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			out = append(out, aggregated)
+		}
+	}
+	return out, nil
+}
+
+func groupKey(row result.Row, fields []string) string {
+	var b strings.Builder
+	for _, field := range fields {
+		v, _ := row.GetString(field)
+		b.WriteString(v)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func compute(agg config.Aggregate, group []result.Row) (any, error) {
+	if agg.Function == config.AggregateCount && agg.Source == "" {
+		return int64(len(group)), nil
+	}
+
+	var values []any
+	for _, row := range group {
+		if v, ok := row.Get(agg.Source); ok && v != nil {
+			values = append(values, v)
+		}
+	}
+
+	switch agg.Function {
+	case config.AggregateCount:
+		return int64(len(values)), nil
+	case config.AggregateDistinctCount:
+		return int64(len(distinct(values, 0))), nil
+	case config.AggregateMin, config.AggregateMax:
+		var best any
+		for _, v := range values {
+			if best == nil {
+				best = v
+				continue
+			}
+			c := compare(v, best)
+			if agg.Function == config.AggregateMin && c < 0 || agg.Function == config.AggregateMax && c > 0 {
+				best = v
+			}
+		}
+		return best, nil
+	case config.AggregateSum:
+		return sum(values)
+	case config.AggregateValues:
+		return distinct(values, agg.Limit), nil
+	default:
+		return nil, fmt.Errorf("unsupported function '%s'", agg.Function)
+	}
+}
+
+// distinct returns the distinct values by their formatted value, keeping at most limit values if
+// limit is set.
+func distinct(values []any, limit int) []any {
+	seen := make(map[string]bool)
+	out := make([]any, 0)
+	for _, v := range values {
+		key := result.FormatValue(v)
+		if seen[key] {
+			continue
+		}
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		seen[key] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// compare orders numbers, including numeric strings, by value and other values by their formatted
+// value, so RFC3339 timestamps are ordered chronologically.
+func compare(a, b any) int {
+	fa, aNum := toFloat(a)
+	fb, bNum := toFloat(b)
+	if aNum && bNum {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(result.FormatValue(a), result.FormatValue(b))
+}
+
+// sum adds the values as int64 as long as they are all integral, and as float64 otherwise.
+func sum(values []any) (any, error) {
+	var total int64
+	var floatTotal float64
+	integral := true
+	for _, v := range values {
+		if integral {
+			if i, ok := toInt(v); ok {
+				total += i
+				continue
+			}
+			integral = false
+			floatTotal = float64(total)
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot sum non-numeric value %q", result.FormatValue(v))
+		}
+		floatTotal += f
+	}
+	if integral {
+		return total, nil
+	}
+	return floatTotal, nil
+}
+
+func having(row result.Row, conditions []config.Having) (bool, error) {
+	for _, h := range conditions {
+		v, _ := row.Get(h.Field)
+		f, ok := toFloat(v)
+		if !ok {
+			return false, fmt.Errorf("having: field %s has non-numeric value %q", h.Field, result.FormatValue(v))
+		}
+		if !operators[h.Operator](f, h.Value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func toInt(v any) (int64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return val, true
+	case int:
+		return int64(val), true
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,200 @@
+package aggregation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/aggregation"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestAggregate(t *testing.T) {
+	rows := []result.Row{
+		{"user": map[string]any{"name": "alice"}, "ip": "10.0.0.1", "bytes": int64(10), "ts": "2024-03-01T12:05:00Z"},
+		{"user": map[string]any{"name": "bob"}, "ip": "10.0.0.2", "bytes": "5", "ts": "2024-03-01T12:00:00Z"},
+		{"user": map[string]any{"name": "alice"}, "ip": "10.0.0.3", "bytes": 2.5, "ts": "2024-03-01T12:01:00Z"},
+		{"user": map[string]any{"name": "alice"}, "ip": "10.0.0.1", "bytes": nil, "ts": "2024-03-01T12:09:00Z"},
+	}
+
+	tests := []struct {
+		name       string
+		cfg        config.Aggregation
+		expected   []result.Row
+		errMessage string
+	}{
+		{
+			name: "group by with every function",
+			cfg: config.Aggregation{
+				GroupBy: []string{"user.name"},
+				Aggregates: []config.Aggregate{
+					{Field: "event_count", Function: config.AggregateCount},
+					{Field: "bytes_count", Function: config.AggregateCount, Source: "bytes"},
+					{Field: "ip_count", Function: config.AggregateDistinctCount, Source: "ip"},
+					{Field: "first_seen", Function: config.AggregateMin, Source: "ts"},
+					{Field: "last_seen", Function: config.AggregateMax, Source: "ts"},
+					{Field: "total_bytes", Function: config.AggregateSum, Source: "bytes"},
+					{Field: "max_bytes", Function: config.AggregateMax, Source: "bytes"},
+					{Field: "ips", Function: config.AggregateValues, Source: "ip"},
+				},
+			},
+			expected: []result.Row{
+				{
+					"user.name":   "alice",
+					"event_count": int64(3),
+					"bytes_count": int64(2),
+					"ip_count":    int64(2),
+					"first_seen":  "2024-03-01T12:01:00Z",
+					"last_seen":   "2024-03-01T12:09:00Z",
+					"total_bytes": 12.5,
+					"max_bytes":   int64(10),
+					"ips":         []any{"10.0.0.1", "10.0.0.3"},
+				},
+				{
+					"user.name":   "bob",
+					"event_count": int64(1),
+					"bytes_count": int64(1),
+					"ip_count":    int64(1),
+					"first_seen":  "2024-03-01T12:00:00Z",
+					"last_seen":   "2024-03-01T12:00:00Z",
+					"total_bytes": int64(5),
+					"max_bytes":   "5",
+					"ips":         []any{"10.0.0.2"},
+				},
+			},
+		},
+		{
+			name: "having thresholds",
+			cfg: config.Aggregation{
+				GroupBy: []string{"user.name"},
+				Aggregates: []config.Aggregate{
+					{Field: "event_count", Function: config.AggregateCount},
+					{Field: "ips", Function: config.AggregateValues, Source: "ip", Limit: 1},
+				},
+				Having: []config.Having{{Field: "event_count", Operator: "gte", Value: 2}},
+			},
+			expected: []result.Row{
+				{"user.name": "alice", "event_count": int64(3), "ips": []any{"10.0.0.1"}},
+			},
+		},
+		{
+			name: "without group by",
+			cfg: config.Aggregation{
+				Aggregates: []config.Aggregate{{Field: "users", Function: config.AggregateDistinctCount, Source: "user.name"}},
+				Having:     []config.Having{{Field: "users", Operator: "gt", Value: 1}},
+			},
+			expected: []result.Row{{"users": int64(2)}},
+		},
+		{
+			name: "non-numeric sum",
+			cfg: config.Aggregation{
+				Aggregates: []config.Aggregate{{Field: "total", Function: config.AggregateSum, Source: "ip"}},
+			},
+			errMessage: `aggregate total: cannot sum non-numeric value "10.0.0.1"`,
+		},
+		{
+			name: "non-numeric having",
+			cfg: config.Aggregation{
+				Aggregates: []config.Aggregate{{Field: "first_seen", Function: config.AggregateMin, Source: "ts"}},
+				Having:     []config.Having{{Field: "first_seen", Operator: "gt", Value: 1}},
+			},
+			errMessage: "having: field first_seen has non-numeric value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := aggregation.Aggregate(rows, &tt.cfg)
+			if tt.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+					t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected rows (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.Aggregation
+		errMessage string
+	}{
+		{
+			name: "valid",
+			cfg: config.Aggregation{
+				GroupBy:    []string{"user"},
+				Aggregates: []config.Aggregate{{Field: "n", Function: config.AggregateCount}},
+				Having:     []config.Having{{Field: "n", Operator: "gt", Value: 5}},
+			},
+		},
+		{
+			name:       "no aggregates",
+			cfg:        config.Aggregation{GroupBy: []string{"user"}},
+			errMessage: "aggregation requires at least one aggregate",
+		},
+		{
+			name:       "unknown function",
+			cfg:        config.Aggregation{Aggregates: []config.Aggregate{{Field: "n", Function: "avg", Source: "x"}}},
+			errMessage: "aggregate n: unsupported function 'avg'",
+		},
+		{
+			name:       "missing source",
+			cfg:        config.Aggregation{Aggregates: []config.Aggregate{{Field: "n", Function: config.AggregateSum}}},
+			errMessage: "aggregate n: function sum requires a source",
+		},
+		{
+			name: "duplicate field",
+			cfg: config.Aggregation{
+				GroupBy:    []string{"user"},
+				Aggregates: []config.Aggregate{{Field: "user", Function: config.AggregateCount}},
+			},
+			errMessage: "aggregate 1: duplicate field user",
+		},
+		{
+			name:       "limit on another function",
+			cfg:        config.Aggregation{Aggregates: []config.Aggregate{{Field: "n", Function: config.AggregateCount, Limit: 3}}},
+			errMessage: "limit must be positive and only applies to values",
+		},
+		{
+			name: "having unknown field",
+			cfg: config.Aggregation{
+				Aggregates: []config.Aggregate{{Field: "n", Function: config.AggregateCount}},
+				Having:     []config.Having{{Field: "m", Operator: "gt"}},
+			},
+			errMessage: "having 1: unknown field m",
+		},
+		{
+			name: "having unknown operator",
+			cfg: config.Aggregation{
+				Aggregates: []config.Aggregate{{Field: "n", Function: config.AggregateCount}},
+				Having:     []config.Having{{Field: "n", Operator: ">"}},
+			},
+			errMessage: "having 1: unsupported operator '>'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := aggregation.Validate(&tt.cfg)
+			if tt.errMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
@@ -4,17 +4,28 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // GlobalConfig holds the entire global configuration.
 type GlobalConfig struct {
-	OpenSearch OpenSearchConnectors `yaml:"opensearch"`
-	PubSub     PubSubConnectors     `yaml:"pubsub"`
-	BigQuery   BigQueryConnectors   `yaml:"bigquery"`
-	Slack      SlackConnectors      `yaml:"slack"`
-	LLM        LLMConfig            `yaml:"llm"`
+	OpenSearch  OpenSearchConnectors  `yaml:"opensearch"`
+	PubSub      PubSubConnectors      `yaml:"pubsub"`
+	BigQuery    BigQueryConnectors    `yaml:"bigquery"`
+	Slack       SlackConnectors       `yaml:"slack"`
+	Syslog      SyslogConnectors      `yaml:"syslog"`
+	Teams       TeamsConnectors       `yaml:"teams"`
+	GChat       GChatConnectors       `yaml:"gchat"`
+	File        FileConnectors        `yaml:"file"`
+	ObjectStore ObjectStoreConnectors `yaml:"objectstore"`
+	TheHive     TheHiveConnectors     `yaml:"thehive"`
+	Stdout      StdoutConfig          `yaml:"stdout"`
+	LLM         LLMConfig             `yaml:"llm"`
+	State       StateConfig           `yaml:"state"`
+	GeoIP       GeoIPConfig           `yaml:"geoip"`
+	Intel       IntelConfig           `yaml:"intel"`
 }
 
 type OpenSearchConnectors struct {
@@ -33,6 +44,30 @@ type SlackConnectors struct {
 	Instances map[string]SlackConfig `yaml:"instances"`
 }
 
+type SyslogConnectors struct {
+	Instances map[string]SyslogConfig `yaml:"instances"`
+}
+
+type TeamsConnectors struct {
+	Instances map[string]TeamsConfig `yaml:"instances"`
+}
+
+type GChatConnectors struct {
+	Instances map[string]GChatConfig `yaml:"instances"`
+}
+
+type FileConnectors struct {
+	Instances map[string]FileConfig `yaml:"instances"`
+}
+
+type ObjectStoreConnectors struct {
+	Instances map[string]ObjectStoreConfig `yaml:"instances"`
+}
+
+type TheHiveConnectors struct {
+	Instances map[string]TheHiveConfig `yaml:"instances"`
+}
+
 type OpenSearchConfig struct {
 	URL                string `yaml:"url"`
 	Username           string `yaml:"username,omitempty"`
@@ -41,20 +76,89 @@ type OpenSearchConfig struct {
 }
 
 type PubSubConfig struct {
-	ProjectID string `yaml:"projectID"`
-	TopicID   string `yaml:"topicID"`
+	ProjectID        string        `yaml:"projectID"`
+	TopicID          string        `yaml:"topicID,omitempty"`
+	SubscriptionID   string        `yaml:"subscriptionID,omitempty"`
+	MaxMessages      int           `yaml:"maxMessages,omitempty"`
+	Window           time.Duration `yaml:"window,omitempty"`
+	AckDeadline      time.Duration `yaml:"ackDeadline,omitempty"`
+	DelayThreshold   time.Duration `yaml:"delayThreshold,omitempty"`
+	CountThreshold   int           `yaml:"countThreshold,omitempty"`
+	ByteThreshold    int           `yaml:"byteThreshold,omitempty"`
+	OrderingKeyField string        `yaml:"orderingKeyField,omitempty"`
 }
 
 type BigQueryConfig struct {
-	ProjectID string `yaml:"projectID"`
-	DatasetID string `yaml:"datasetID"`
-	TableID   string `yaml:"tableID"`
+	ProjectID        string `yaml:"projectID"`
+	DatasetID        string `yaml:"datasetID"`
+	TableID          string `yaml:"tableID"`
+	CreateTable      bool   `yaml:"createTable,omitempty"`
+	StorageWriteAPI  bool   `yaml:"storageWriteAPI,omitempty"`
+	BatchSize        int    `yaml:"batchSize,omitempty"`
+	Location         string `yaml:"location,omitempty"`
+	DryRunBudget     int64  `yaml:"dryRunBudget,omitempty"`
+	RejectOverBudget bool   `yaml:"rejectOverBudget,omitempty"`
 }
 
 type SlackConfig struct {
+	WebhookURL     string        `yaml:"webhookURL,omitempty"`
+	BotToken       string        `yaml:"botToken,omitempty"`
+	Channel        string        `yaml:"channel,omitempty"`
+	ThreadLookback time.Duration `yaml:"threadLookback,omitempty"`
+}
+
+type SyslogConfig struct {
+	Network            string `yaml:"network"` // udp, tcp or tls
+	Address            string `yaml:"address"`
+	Format             string `yaml:"format"` // cef or leef
+	Facility           string `yaml:"facility,omitempty"`
+	AppName            string `yaml:"appName,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+type TeamsConfig struct {
+	WebhookURL string `yaml:"webhookURL,omitempty"`
+}
+
+type GChatConfig struct {
 	WebhookURL string `yaml:"webhookURL,omitempty"`
 }
 
+type FileConfig struct {
+	Path           string        `yaml:"path"`
+	Format         string        `yaml:"format"` // jsonl, csv or parquet
+	Gzip           bool          `yaml:"gzip"`
+	MaxSize        int64         `yaml:"maxSize,omitempty"` // in bytes
+	RotateInterval time.Duration `yaml:"rotateInterval,omitempty"`
+}
+
+type ObjectStoreConfig struct {
+	Provider        string `yaml:"provider"` // s3 or gcs
+	Bucket          string `yaml:"bucket"`
+	Prefix          string `yaml:"prefix,omitempty"`
+	Endpoint        string `yaml:"endpoint,omitempty"`
+	Region          string `yaml:"region,omitempty"`
+	AccessKeyID     string `yaml:"accessKeyID,omitempty"`
+	SecretAccessKey string `yaml:"secretAccessKey,omitempty"`
+	DisableTLS      bool   `yaml:"disableTLS,omitempty"`
+	Encryption      string `yaml:"encryption,omitempty"` // sse-s3 or sse-kms
+	KMSKeyID        string `yaml:"kmsKeyID,omitempty"`
+}
+
+type TheHiveConfig struct {
+	URL                string `yaml:"url"`
+	APIKey             string `yaml:"apiKey"`
+	Organisation       string `yaml:"organisation,omitempty"`
+	Source             string `yaml:"source,omitempty"`
+	AlertType          string `yaml:"alertType,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+// StdoutConfig configures the always-available stdout publisher.
+type StdoutConfig struct {
+	Format string `yaml:"format,omitempty"` // jsonl (default) or csv
+}
+
 type LLMConfig struct {
 	Provider    string  `yaml:"provider"`
 	APIKey      string  `yaml:"apiKey"`
@@ -63,6 +167,41 @@ type LLMConfig struct {
 	Temperature float64 `yaml:"temperature"`
 }
 
+// StateConfig configures where state kept across runs, such as suppression windows, is stored.
+type StateConfig struct {
+	// Path is the directory state files are written to, e.g. a persistent volume.
+	Path string `yaml:"path"`
+}
+
+// GeoIPConfig locates the local MaxMind databases used by GeoIP enrichment, e.g. GeoLite2-City.mmdb
+// and GeoLite2-ASN.mmdb. Either can be omitted.
+type GeoIPConfig struct {
+	CityDatabase string `yaml:"cityDatabase,omitempty"`
+	ASNDatabase  string `yaml:"asnDatabase,omitempty"`
+}
+
+// IntelConfig holds the threat-intel indicator feeds rules can match results against, by name.
+type IntelConfig struct {
+	Feeds map[string]IntelFeed `yaml:"feeds"`
+}
+
+// IntelFeed is a local file of indicators: IPs, CIDRs, domains, hashes or URLs.
+type IntelFeed struct {
+	Path string `yaml:"path"`
+	// Format is plain (one indicator per line), csv (with a header row) or stix (a STIX 2.1 bundle).
+	Format string `yaml:"format"`
+	// Type is the type of all indicators of a plain or csv feed: ip, cidr, domain, hash or url.
+	// Detected per indicator if empty.
+	Type string `yaml:"type,omitempty"`
+	// Confidence (0-100) of indicators that don't set their own.
+	Confidence int `yaml:"confidence,omitempty"`
+	// IndicatorColumn, TypeColumn and ConfidenceColumn name the columns of csv feeds. They default
+	// to indicator, type and confidence; the type and confidence columns are optional.
+	IndicatorColumn  string `yaml:"indicatorColumn,omitempty"`
+	TypeColumn       string `yaml:"typeColumn,omitempty"`
+	ConfidenceColumn string `yaml:"confidenceColumn,omitempty"`
+}
+
 // ParseGlobalConfig parses the global YAML configuration file.
 func ParseGlobalConfig(path string) (*GlobalConfig, error) {
 	var cfg GlobalConfig
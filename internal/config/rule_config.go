@@ -3,17 +3,25 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // RuleConfig represents the entire config structure for a single rule.
 type RuleConfig struct {
+	Aggregation    *Aggregation    `yaml:"aggregation,omitempty"`
 	Author         string          `yaml:"author"`
+	BigQuery       *BigQuery       `yaml:"bigquery,omitempty"`
 	Confidence     ConfidenceLevel `yaml:"confidence"`
+	Correlation    *Correlation    `yaml:"correlation,omitempty"`
 	Description    string          `yaml:"description"`
 	Enabled        bool            `yaml:"enabled"`
+	Enrichments    []Enrichment    `yaml:"enrichments,omitempty"`
 	ExclusionsPath string          `yaml:"exclusionsPath,omitempty"`
+	Fingerprint    *Fingerprint    `yaml:"fingerprint,omitempty"`
+	GeoIP          *GeoIP          `yaml:"geoip,omitempty"`
+	Intel          *Intel          `yaml:"intel,omitempty"`
 	Language       string          `yaml:"language"`
 	LLM            *LLM            `yaml:"llm,omitempty"`
 	Name           string          `yaml:"name"`
@@ -23,7 +31,11 @@ type RuleConfig struct {
 	QueryEngine    string          `yaml:"queryEngine"`
 	References     []string        `yaml:"references"`
 	Schedule       string          `yaml:"schedule"`
+	Scoring        *Scoring        `yaml:"scoring,omitempty"`
+	Severity       SeverityLevel   `yaml:"severity,omitempty"`
+	Slack          *Slack          `yaml:"slack,omitempty"`
 	Status         string          `yaml:"status"`
+	Suppression    *Suppression    `yaml:"suppression,omitempty"`
 	Tags           []string        `yaml:"tags"`
 	TTPs           []TTP           `yaml:"ttps"`
 	UID            string          `yaml:"uid"`
@@ -34,6 +46,195 @@ type LLM struct {
 	Prompt  string `yaml:"prompt"`
 }
 
+// Aggregation groups query results inside Venator for query engines without GROUP BY and HAVING.
+// Every group becomes a single result with its group-by fields and aggregates.
+type Aggregation struct {
+	// GroupBy are the result fields whose values identify a group. Without any, all results form a
+	// single group.
+	GroupBy    []string    `yaml:"groupBy,omitempty"`
+	Aggregates []Aggregate `yaml:"aggregates"`
+	// Having keeps the groups matching all conditions.
+	Having []Having `yaml:"having,omitempty"`
+}
+
+// Aggregate computes Field from the Source values of a group.
+type Aggregate struct {
+	Field    string            `yaml:"field"`
+	Function AggregateFunction `yaml:"function"`
+	// Source is the aggregated result field. Count counts results without one.
+	Source string `yaml:"source,omitempty"`
+	// Limit caps the number of values collected by the values function.
+	Limit int `yaml:"limit,omitempty"`
+}
+
+// Having compares an aggregated field against a number.
+type Having struct {
+	Field string `yaml:"field"`
+	// Operator is one of gt, gte, lt, lte, equals or not_equals.
+	Operator string  `yaml:"operator"`
+	Value    float64 `yaml:"value"`
+}
+
+type AggregateFunction string
+
+const (
+	AggregateCount         AggregateFunction = "count"
+	AggregateDistinctCount AggregateFunction = "distinct_count"
+	AggregateMin           AggregateFunction = "min"
+	AggregateMax           AggregateFunction = "max"
+	AggregateSum           AggregateFunction = "sum"
+	// AggregateValues collects the distinct values in the order they were seen.
+	AggregateValues AggregateFunction = "values"
+)
+
+// Correlation runs several stage queries, possibly on different query engines, and correlates
+// their results by key fields and time instead of running the rule's own query. Every match becomes
+// a single result with the key fields, first_seen, last_seen and the contributing events by stage.
+type Correlation struct {
+	Type CorrelationType `yaml:"type"`
+	// Keys are the result fields whose values must be equal across stages, e.g. user.name.
+	Keys []string `yaml:"keys"`
+	// Within is the maximum time between the first and the last event of a match.
+	Within time.Duration `yaml:"within"`
+	// TimestampField is the result field events are ordered by, an RFC3339 timestamp or a common SQL
+	// layout such as 2023-10-10 08:05:00 UTC. Defaults to timestamp.
+	TimestampField string             `yaml:"timestampField,omitempty"`
+	Stages         []CorrelationStage `yaml:"stages"`
+}
+
+// CorrelationStage is a query whose results are correlated with the other stages. The stage query
+// runs with the rule config, its query fields replaced by the stage's.
+type CorrelationStage struct {
+	Name        string    `yaml:"name"`
+	QueryEngine string    `yaml:"queryEngine"`
+	Language    string    `yaml:"language"`
+	Query       string    `yaml:"query"`
+	BigQuery    *BigQuery `yaml:"bigquery,omitempty"`
+	// Keys override the correlation keys for stages naming them differently, in the same order.
+	Keys []string `yaml:"keys,omitempty"`
+	// TimestampField overrides the correlation timestamp field.
+	TimestampField string `yaml:"timestampField,omitempty"`
+}
+
+type CorrelationType string
+
+const (
+	// CorrelationJoin matches events of every stage in any order.
+	CorrelationJoin CorrelationType = "join"
+	// CorrelationSequence matches events of every stage in the order of the stages.
+	CorrelationSequence CorrelationType = "sequence"
+)
+
+// Enrichment joins results with a lookup table, such as an asset or identity inventory, and adds
+// the columns of the matching entry to the results.
+type Enrichment struct {
+	// Path is a CSV file with a header row or a JSON file holding an array of objects.
+	Path string `yaml:"path,omitempty"`
+	// QueryEngine, Language and Query load the table from a query runner instead of a file.
+	QueryEngine string `yaml:"queryEngine,omitempty"`
+	Language    string `yaml:"language,omitempty"`
+	Query       string `yaml:"query,omitempty"`
+	// Field is the result field looked up in the Key column of the table.
+	Field string `yaml:"field"`
+	Key   string `yaml:"key"`
+	// CaseInsensitive ignores the case of the field and key values.
+	CaseInsensitive bool `yaml:"caseInsensitive,omitempty"`
+	// Fields copies table columns (source) to result fields (field), e.g. actor.user.department.
+	Fields []EnrichmentField `yaml:"fields"`
+}
+
+type EnrichmentField struct {
+	Field  string `yaml:"field"`
+	Source string `yaml:"source"`
+}
+
+// GeoIP adds the location, ASN and classification of IP addresses to the results, from the
+// databases of the global geoip config.
+type GeoIP struct {
+	// Fields are the result fields holding IP addresses. Defaults to the sources of the
+	// src_endpoint.ip and dst_endpoint.ip output fields.
+	Fields []string `yaml:"fields,omitempty"`
+}
+
+// Intel matches result fields against the indicator feeds of the global intel config and tags
+// matching results with the matched indicator, feed and confidence.
+type Intel struct {
+	// Feeds are the names of the feeds to match against. Defaults to all feeds.
+	Feeds []string `yaml:"feeds,omitempty"`
+	// Fields are the result fields matched against the indicators.
+	Fields []string `yaml:"fields"`
+	// MinConfidence ignores indicators with a lower confidence (0-100).
+	MinConfidence int `yaml:"minConfidence,omitempty"`
+	// Require drops results without a match.
+	Require bool `yaml:"require,omitempty"`
+	// Confidence raises the confidence of findings with a match to this level.
+	Confidence ConfidenceLevel `yaml:"confidence,omitempty"`
+}
+
+// Scoring sets the confidence, severity and risk score of each finding from conditions on its
+// result fields, and accumulates the risk scores of the findings of a run per entity.
+type Scoring struct {
+	// Lists are named string lists the conditions can reference, e.g. actor.user.name in admins.
+	Lists map[string][]string `yaml:"lists,omitempty"`
+	// Rules are applied to every finding in order.
+	Rules []ScoringRule `yaml:"rules"`
+	// Entity is the result field identifying the entity, e.g. actor.user.name, whose findings'
+	// risk scores are summed.
+	Entity string `yaml:"entity,omitempty"`
+	// MinEntityRisk drops findings whose entity's risk score is lower. Findings without an entity
+	// are compared by their own risk score.
+	MinEntityRisk int `yaml:"minEntityRisk,omitempty"`
+}
+
+// ScoringRule adjusts the findings matching a condition.
+type ScoringRule struct {
+	// When is a condition on the result fields, e.g. bytes_out > 1e9, in the expression language
+	// of the expr package.
+	When string `yaml:"when"`
+	// Confidence and Severity set the level of matching findings, e.g. high, or move it by a
+	// number of levels, e.g. +1 or -1.
+	Confidence string `yaml:"confidence,omitempty"`
+	Severity   string `yaml:"severity,omitempty"`
+	// Risk is added to the risk score of matching findings.
+	Risk int `yaml:"risk,omitempty"`
+}
+
+// BigQuery customizes how the rule's query is run by BigQuery query runners.
+type BigQuery struct {
+	// Window is the time range covered by each run. The query can reference it through the
+	// @window_start and @window_end TIMESTAMP parameters, ending at the time of the run.
+	Window time.Duration `yaml:"window,omitempty"`
+	// MaximumBytesBilled fails the query instead of billing more than this many bytes.
+	MaximumBytesBilled int64 `yaml:"maximumBytesBilled,omitempty"`
+	// Labels are added to the query job, next to the rule name and UID labels.
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// Fingerprint configures how finding UIDs are derived. Findings with the same rule UID, key field
+// values and window start get the same UID, so retries are deduplicated downstream.
+type Fingerprint struct {
+	// Fields are the result fields identifying a finding. Defaults to the whole result.
+	Fields []string `yaml:"fields,omitempty"`
+	// Window buckets findings by their timestamp truncated to the window, e.g. 1h.
+	Window time.Duration `yaml:"window,omitempty"`
+}
+
+// Suppression drops findings already published by a previous run. Findings are identified by
+// their fingerprint, so a fingerprint window shorter than SuppressFor ends suppression early.
+type Suppression struct {
+	// SuppressFor is how long findings with the same fingerprint are dropped after one was published.
+	SuppressFor time.Duration `yaml:"suppressFor"`
+	// Rollup publishes the last suppressed finding with the number of suppressed findings once the
+	// window ended.
+	Rollup bool `yaml:"rollup,omitempty"`
+}
+
+// Slack customizes how findings of the rule are rendered by Slack publishers.
+type Slack struct {
+	// Template is a Go template rendering a JSON array of Block Kit blocks for a single finding.
+	Template string `yaml:"template,omitempty"`
+}
+
 type Output struct {
 	Format OutputFormat  `yaml:"format"`
 	Fields []OutputField `yaml:"fields"`
@@ -42,6 +243,41 @@ type Output struct {
 type OutputField struct {
 	Field  string `yaml:"field"`
 	Source string `yaml:"source"`
+	// Sources concatenates several result fields, joined by Separator, instead of reading Source.
+	Sources   []string `yaml:"sources,omitempty"`
+	Separator string   `yaml:"separator,omitempty"`
+	// Default is used as is when the source is missing or null.
+	Default *string `yaml:"default,omitempty"`
+	// Transforms are applied to the source value in order.
+	Transforms []Transform `yaml:"transforms,omitempty"`
+}
+
+// Transform is a single step of an output field transformation. Exactly one option is set.
+type Transform struct {
+	// Timestamp parses the value as epoch_seconds, epoch_millis, epoch_micros, epoch_nanos or a Go
+	// time layout, and formats it as RFC3339.
+	Timestamp string `yaml:"timestamp,omitempty"`
+	Lowercase bool   `yaml:"lowercase,omitempty"`
+	Uppercase bool   `yaml:"uppercase,omitempty"`
+	Trim      bool   `yaml:"trim,omitempty"`
+	// Regex extracts the first capture group of the expression, or the whole match without groups.
+	Regex string `yaml:"regex,omitempty"`
+	Split *Split `yaml:"split,omitempty"`
+	// Hash replaces the value by its hex digest: sha256, sha1 or md5.
+	Hash string `yaml:"hash,omitempty"`
+	// Redact replaces the value, keeping the last Keep characters.
+	Redact *Redact `yaml:"redact,omitempty"`
+}
+
+// Split splits the value by Separator. With Index, the element at Index is kept (negative indexes
+// count from the end), otherwise the value becomes a list.
+type Split struct {
+	Separator string `yaml:"separator"`
+	Index     *int   `yaml:"index,omitempty"`
+}
+
+type Redact struct {
+	Keep int `yaml:"keep,omitempty"`
 }
 
 type TTP struct {
@@ -61,13 +297,38 @@ const (
 	ConfidenceHigh    ConfidenceLevel = "high"
 )
 
+// SeverityLevel is the impact of a finding, following the OCSF severity scale.
+type SeverityLevel string
+
+const (
+	SeverityUnknown       SeverityLevel = "unknown"
+	SeverityInformational SeverityLevel = "informational"
+	SeverityLow           SeverityLevel = "low"
+	SeverityMedium        SeverityLevel = "medium"
+	SeverityHigh          SeverityLevel = "high"
+	SeverityCritical      SeverityLevel = "critical"
+)
+
 type OutputFormat string
 
 const (
 	OutputFormatRaw    OutputFormat = "raw"
 	OutputFormatSignal OutputFormat = "signal"
+	// OutputFormatOCSF emits OCSF Detection Findings built from the signal field mapping.
+	OutputFormatOCSF OutputFormat = "ocsf"
+	// OutputFormatECS emits Elastic Common Schema documents for Elastic/OpenSearch security views.
+	OutputFormatECS OutputFormat = "ecs"
+	// OutputFormatSTIX emits STIX 2.1 bundles with an Indicator for the rule, Attack-Patterns for
+	// its TTPs and a Sighting of the Indicator.
+	OutputFormatSTIX OutputFormat = "stix"
 )
 
+// MapsFields reports whether the output format is built from the field mapping rather than
+// passing results through as is.
+func (o Output) MapsFields() bool {
+	return o.Format != OutputFormatRaw
+}
+
 // ParseRuleConfig parses the rules YAML configuration file.
 func ParseRuleConfig(path string) (*RuleConfig, error) {
 	var cfg RuleConfig
@@ -0,0 +1,269 @@
+// Package correlation correlates the results of several stage queries by key fields and time.
+package correlation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "internal/correlation")
+
+// Fields of correlated results, next to the correlation keys.
+const (
+	FirstSeenField = "first_seen"
+	LastSeenField  = "last_seen"
+	// EventsField holds the contributing event of every stage by stage name, so output fields can
+	// reference them as events.<stage>.<field>.
+	EventsField = "events"
+)
+
+const defaultTimestampField = "timestamp"
+
+type event struct {
+	stage int
+	time  time.Time
+	row   result.Row
+}
+
+// Validate checks the correlation config, so mistakes are reported when the rule is loaded.
+func Validate(cfg *config.Correlation) error {
+	if cfg.Type != config.CorrelationJoin && cfg.Type != config.CorrelationSequence {
+		return fmt.Errorf("unsupported correlation type '%s'", cfg.Type)
+	}
+	if len(cfg.Keys) == 0 {
+		return fmt.Errorf("correlation requires at least one key")
+	}
+	if cfg.Within <= 0 {
+		return fmt.Errorf("correlation requires a positive within duration")
+	}
+	if len(cfg.Stages) < 2 {
+		return fmt.Errorf("correlation requires at least two stages")
+	}
+	names := make(map[string]bool)
+	for i, stage := range cfg.Stages {
+		if stage.Name == "" {
+			return fmt.Errorf("stage %d: name is required", i+1)
+		}
+		if names[stage.Name] {
+			return fmt.Errorf("stage %d: duplicate name %s", i+1, stage.Name)
+		}
+		names[stage.Name] = true
+		if stage.QueryEngine == "" {
+			return fmt.Errorf("stage %s: queryEngine is required", stage.Name)
+		}
+		if len(stage.Keys) > 0 && len(stage.Keys) != len(cfg.Keys) {
+			return fmt.Errorf("stage %s: expected %d keys, got %d", stage.Name, len(cfg.Keys), len(stage.Keys))
+		}
+	}
+	return nil
+}
+
+// StageRule returns the rule config the query of stage runs with.
+func StageRule(rule *config.RuleConfig, stage config.CorrelationStage) *config.RuleConfig {
+	stageRule := *rule
+	stageRule.QueryEngine = stage.QueryEngine
+	stageRule.Language = stage.Language
+	stageRule.Query = stage.Query
+	stageRule.BigQuery = stage.BigQuery
+	stageRule.Correlation = nil
+	return &stageRule
+}
+
+// Correlate matches the results of every stage, given in the order of the stages, and returns one
+// result per match ordered by first_seen. Events are matched by equal key values and are at most
+// Within apart; sequences require every event to be at or after the event of the previous stage.
+// An event contributes to at most one match, and results missing a key or a parsable timestamp are
+// ignored.
+func Correlate(results [][]result.Row, cfg *config.Correlation) ([]result.Row, error) {
+	if len(results) != len(cfg.Stages) {
+		return nil, fmt.Errorf("expected results of %d stages, got %d", len(cfg.Stages), len(results))
+	}
+
+	var keys []string
+	groups := make(map[string][]event)
+	skipped, invalid := 0, 0
+	for i, rows := range results {
+		stage := cfg.Stages[i]
+		keyFields := cfg.Keys
+		if len(stage.Keys) > 0 {
+			keyFields = stage.Keys
+		}
+		tsField := stage.TimestampField
+		if tsField == "" {
+			tsField = cfg.TimestampField
+		}
+		if tsField == "" {
+			tsField = defaultTimestampField
+		}
+
+		for _, row := range rows {
+			key, ok := groupKey(row, keyFields)
+			if !ok {
+				skipped++
+				continue
+			}
+			value, _ := row.Get(tsField)
+			ts, ok := parseTimestamp(value)
+			if !ok {
+				invalid++
+				logger.Debugf("stage %s: ignored result with unparsable timestamp field %s: %v", stage.Name, tsField, value)
+				continue
+			}
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], event{stage: i, time: ts, row: row})
+		}
+	}
+	if skipped > 0 {
+		logger.Debugf("ignored %d result(s) missing a correlation key", skipped)
+	}
+	if invalid > 0 {
+		logger.Warnf("ignored %d result(s) with a missing or unparsable timestamp", invalid)
+	}
+
+	var all [][]event
+	for _, key := range keys {
+		all = append(all, matches(groups[key], cfg)...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return firstEvent(all[i]).time.Before(firstEvent(all[j]).time)
+	})
+
+	var out []result.Row
+	for _, match := range all {
+		out = append(out, combine(match, cfg))
+	}
+	return out, nil
+}
+
+// timestampLayouts are the timestamp formats accepted besides RFC 3339, as returned by query engines,
+// e.g. 2023-10-10 08:05:00 UTC by BigQuery. Timestamps without a zone are in UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 MST",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseTimestamp returns the time of a timestamp value, or false if it is missing or has an unknown format.
+func parseTimestamp(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, false
+	case time.Time:
+		return v, true
+	}
+	s := result.FormatValue(value)
+	for _, layout := range timestampLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// groupKey returns the key values of row, or false if one is missing or null.
+func groupKey(row result.Row, fields []string) (string, bool) {
+	var b strings.Builder
+	for _, field := range fields {
+		v, ok := row.Get(field)
+		if !ok || v == nil {
+			return "", false
+		}
+		b.WriteString(result.FormatValue(v))
+		b.WriteByte(0)
+	}
+	return b.String(), true
+}
+
+// matches returns the matches among the events of a key. Starting from the earliest event, each
+// stage takes its first event within the window, and the next match starts after the last event
+// of the previous one.
+func matches(events []event, cfg *config.Correlation) [][]event {
+	sequence := cfg.Type == config.CorrelationSequence
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].time.Equal(events[j].time) {
+			return events[i].time.Before(events[j].time)
+		}
+		return events[i].stage < events[j].stage
+	})
+
+	var out [][]event
+	for start := 0; start < len(events); start++ {
+		if sequence && events[start].stage != 0 {
+			continue
+		}
+		picked := make([]*event, len(cfg.Stages))
+		found, last := 0, start
+		for j := start; j < len(events) && found < len(picked); j++ {
+			e := &events[j]
+			if e.time.Sub(events[start].time) > cfg.Within {
+				break
+			}
+			if picked[e.stage] != nil || sequence && e.stage != found {
+				continue
+			}
+			picked[e.stage] = e
+			found++
+			last = j
+		}
+		if found < len(picked) {
+			continue
+		}
+
+		match := make([]event, len(picked))
+		for i, e := range picked {
+			match[i] = *e
+		}
+		out = append(out, match)
+		start = last
+	}
+	return out
+}
+
+func firstEvent(match []event) event {
+	first := match[0]
+	for _, e := range match {
+		if e.time.Before(first.time) {
+			first = e
+		}
+	}
+	return first
+}
+
+// combine builds the result of a match, taking the key values from its first event.
+func combine(match []event, cfg *config.Correlation) result.Row {
+	first, last := firstEvent(match), match[0]
+	for _, e := range match {
+		if e.time.After(last.time) {
+			last = e
+		}
+	}
+
+	row := make(result.Row)
+	firstKeys := cfg.Keys
+	if stageKeys := cfg.Stages[first.stage].Keys; len(stageKeys) > 0 {
+		firstKeys = stageKeys
+	}
+	for i, key := range cfg.Keys {
+		row[key], _ = first.row.Get(firstKeys[i])
+	}
+	row[FirstSeenField] = first.time.UTC().Format(time.RFC3339Nano)
+	row[LastSeenField] = last.time.UTC().Format(time.RFC3339Nano)
+
+	events := make(map[string]any, len(match))
+	for _, e := range match {
+		events[cfg.Stages[e.stage].Name] = map[string]any(e.row)
+	}
+	row[EventsField] = events
+	return row
+}
@@ -0,0 +1,244 @@
+package correlation_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/correlation"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestCorrelate(t *testing.T) {
+	logins := []result.Row{
+		{"timestamp": "2024-03-01T12:00:00Z", "user": "alice", "ip": "203.0.113.1"},
+		{"timestamp": "2024-03-01T12:20:00Z", "user": "alice", "ip": "203.0.113.2"},
+		{"timestamp": "2024-03-01T12:02:00Z", "user": "bob", "ip": "203.0.113.3"},
+		{"timestamp": "2024-03-01T12:03:00Z", "ip": "203.0.113.4"},
+	}
+	changes := []result.Row{
+		{"time": "2024-03-01T11:59:00Z", "actor": map[string]any{"name": "bob"}, "action": "mfa_disabled"},
+		{"time": "2024-03-01T12:05:00Z", "actor": map[string]any{"name": "alice"}, "action": "mfa_disabled"},
+		{"time": "2024-03-01T12:25:00Z", "actor": map[string]any{"name": "alice"}, "action": "key_created"},
+		{"time": "2024-03-01T12:08:00Z", "actor": map[string]any{"name": "carol"}, "action": "key_created"},
+	}
+	stages := []config.CorrelationStage{
+		{Name: "login", QueryEngine: "opensearch.logs"},
+		{Name: "change", QueryEngine: "bigquery.audit", Keys: []string{"actor.name"}, TimestampField: "time"},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.Correlation
+		expected []result.Row
+	}{
+		{
+			name: "sequence",
+			cfg:  config.Correlation{Type: config.CorrelationSequence, Keys: []string{"user"}, Within: 10 * time.Minute, Stages: stages},
+			expected: []result.Row{
+				{
+					"user":       "alice",
+					"first_seen": "2024-03-01T12:00:00Z",
+					"last_seen":  "2024-03-01T12:05:00Z",
+					"events": map[string]any{
+						"login":  map[string]any(logins[0]),
+						"change": map[string]any(changes[1]),
+					},
+				},
+				{
+					"user":       "alice",
+					"first_seen": "2024-03-01T12:20:00Z",
+					"last_seen":  "2024-03-01T12:25:00Z",
+					"events": map[string]any{
+						"login":  map[string]any(logins[1]),
+						"change": map[string]any(changes[2]),
+					},
+				},
+			},
+		},
+		{
+			name: "join in any order",
+			cfg:  config.Correlation{Type: config.CorrelationJoin, Keys: []string{"user"}, Within: 5 * time.Minute, Stages: stages},
+			expected: []result.Row{
+				{
+					"user":       "bob",
+					"first_seen": "2024-03-01T11:59:00Z",
+					"last_seen":  "2024-03-01T12:02:00Z",
+					"events": map[string]any{
+						"login":  map[string]any(logins[2]),
+						"change": map[string]any(changes[0]),
+					},
+				},
+				{
+					"user":       "alice",
+					"first_seen": "2024-03-01T12:00:00Z",
+					"last_seen":  "2024-03-01T12:05:00Z",
+					"events": map[string]any{
+						"login":  map[string]any(logins[0]),
+						"change": map[string]any(changes[1]),
+					},
+				},
+				{
+					"user":       "alice",
+					"first_seen": "2024-03-01T12:20:00Z",
+					"last_seen":  "2024-03-01T12:25:00Z",
+					"events": map[string]any{
+						"login":  map[string]any(logins[1]),
+						"change": map[string]any(changes[2]),
+					},
+				},
+			},
+		},
+		{
+			name: "outside the window",
+			cfg:  config.Correlation{Type: config.CorrelationSequence, Keys: []string{"user"}, Within: time.Minute, Stages: stages},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := correlation.Correlate([][]result.Row{logins, changes}, &tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected results (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCorrelateTimestampFormats(t *testing.T) {
+	cfg := &config.Correlation{
+		Type:   config.CorrelationJoin,
+		Keys:   []string{"user"},
+		Within: time.Minute,
+		Stages: []config.CorrelationStage{{Name: "a"}, {Name: "b"}},
+	}
+	results := [][]result.Row{
+		{
+			{"user": "alice", "timestamp": "2023-10-10 08:05:00 UTC"},
+			{"user": "bob", "timestamp": "yesterday"},
+			{"user": "carol", "timestamp": nil},
+		},
+		{
+			{"user": "alice", "timestamp": "2023-10-10T08:05:30.5Z"},
+			{"user": "bob", "timestamp": "2023-10-10 08:05:00"},
+			{"user": "carol", "timestamp": "2023-10-10 08:05:00+00:00"},
+		},
+	}
+
+	// Results with an unparsable timestamp are ignored instead of failing the correlation.
+	got, err := correlation.Correlate(results, cfg)
+	if err != nil {
+		t.Fatalf("Correlate() unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0]["user"] != "alice" {
+		t.Fatalf("expected a single match of alice, got %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	stages := []config.CorrelationStage{
+		{Name: "a", QueryEngine: "opensearch.logs"},
+		{Name: "b", QueryEngine: "bigquery.audit"},
+	}
+
+	tests := []struct {
+		name       string
+		cfg        config.Correlation
+		errMessage string
+	}{
+		{
+			name: "valid",
+			cfg:  config.Correlation{Type: config.CorrelationJoin, Keys: []string{"user"}, Within: time.Minute, Stages: stages},
+		},
+		{
+			name:       "unknown type",
+			cfg:        config.Correlation{Type: "union", Keys: []string{"user"}, Within: time.Minute, Stages: stages},
+			errMessage: "unsupported correlation type 'union'",
+		},
+		{
+			name:       "no keys",
+			cfg:        config.Correlation{Type: config.CorrelationJoin, Within: time.Minute, Stages: stages},
+			errMessage: "correlation requires at least one key",
+		},
+		{
+			name:       "no window",
+			cfg:        config.Correlation{Type: config.CorrelationJoin, Keys: []string{"user"}, Stages: stages},
+			errMessage: "correlation requires a positive within duration",
+		},
+		{
+			name:       "single stage",
+			cfg:        config.Correlation{Type: config.CorrelationJoin, Keys: []string{"user"}, Within: time.Minute, Stages: stages[:1]},
+			errMessage: "correlation requires at least two stages",
+		},
+		{
+			name: "duplicate stage",
+			cfg: config.Correlation{
+				Type: config.CorrelationJoin, Keys: []string{"user"}, Within: time.Minute,
+				Stages: []config.CorrelationStage{stages[0], stages[0]},
+			},
+			errMessage: "stage 2: duplicate name a",
+		},
+		{
+			name: "missing query engine",
+			cfg: config.Correlation{
+				Type: config.CorrelationJoin, Keys: []string{"user"}, Within: time.Minute,
+				Stages: []config.CorrelationStage{stages[0], {Name: "b"}},
+			},
+			errMessage: "stage b: queryEngine is required",
+		},
+		{
+			name: "stage keys mismatch",
+			cfg: config.Correlation{
+				Type: config.CorrelationJoin, Keys: []string{"user"}, Within: time.Minute,
+				Stages: []config.CorrelationStage{stages[0], {Name: "b", QueryEngine: "bigquery.audit", Keys: []string{"a", "b"}}},
+			},
+			errMessage: "stage b: expected 1 keys, got 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := correlation.Validate(&tt.cfg)
+			if tt.errMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+func TestStageRule(t *testing.T) {
+	rule := &config.RuleConfig{
+		Name:        "rule",
+		QueryEngine: "unused",
+		Correlation: &config.Correlation{},
+	}
+	stage := config.CorrelationStage{
+		Name:        "a",
+		QueryEngine: "bigquery.audit",
+		Language:    "SQL",
+		Query:       "SELECT 1",
+		BigQuery:    &config.BigQuery{Window: time.Hour},
+	}
+	expected := &config.RuleConfig{
+		Name:        "rule",
+		QueryEngine: "bigquery.audit",
+		Language:    "SQL",
+		Query:       "SELECT 1",
+		BigQuery:    &config.BigQuery{Window: time.Hour},
+	}
+	if diff := cmp.Diff(expected, correlation.StageRule(rule, stage)); diff != "" {
+		t.Errorf("unexpected stage rule (-want +got):\n%s", diff)
+	}
+}
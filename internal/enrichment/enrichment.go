@@ -0,0 +1,164 @@
+// Package enrichment adds context from lookup tables, such as asset and identity inventories, to
+// query results.
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// QueryFunc runs the query of a rule config, used to load tables from query runners.
+type QueryFunc func(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error)
+
+// Enricher joins results with the lookup tables of a rule.
+type Enricher struct {
+	tables []table
+}
+
+type table struct {
+	cfg     config.Enrichment
+	entries map[string]result.Row
+}
+
+// New loads the lookup tables of the rule. Tables with a query are loaded through query.
+func New(ctx context.Context, rule *config.RuleConfig, query QueryFunc) (*Enricher, error) {
+	e := &Enricher{}
+	for i, cfg := range rule.Enrichments {
+		if err := validate(cfg); err != nil {
+			return nil, fmt.Errorf("enrichment %d: %w", i+1, err)
+		}
+
+		var rows []result.Row
+		var err error
+		if cfg.Path != "" {
+			rows, err = loadFile(cfg.Path)
+		} else {
+			tableRule := *rule
+			tableRule.QueryEngine = cfg.QueryEngine
+			tableRule.Language = cfg.Language
+			tableRule.Query = cfg.Query
+			rows, err = query(ctx, &tableRule)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("enrichment %d: failed to load lookup table: %w", i+1, err)
+		}
+
+		t := table{cfg: cfg, entries: make(map[string]result.Row)}
+		for _, row := range rows {
+			key, ok := t.key(row, cfg.Key)
+			if !ok {
+				continue
+			}
+			// The first entry of a key wins, like the first matching row of a SQL lookup.
+			if _, ok := t.entries[key]; !ok {
+				t.entries[key] = row
+			}
+		}
+		e.tables = append(e.tables, t)
+	}
+	return e, nil
+}
+
+// Enrich sets the fields of every lookup table entry matching a row. Tables are applied in order,
+// so a table can look up a field added by a previous one. Rows without a match are left as is.
+func (e *Enricher) Enrich(rows []result.Row) {
+	for _, row := range rows {
+		for _, t := range e.tables {
+			key, ok := t.key(row, t.cfg.Field)
+			if !ok {
+				continue
+			}
+			entry, ok := t.entries[key]
+			if !ok {
+				continue
+			}
+			for _, f := range t.cfg.Fields {
+				if v, ok := entry.Get(f.Source); ok {
+					row.Set(f.Field, v)
+				}
+			}
+		}
+	}
+}
+
+func (t table) key(row result.Row, field string) (string, bool) {
+	v, ok := row.Get(field)
+	if !ok || v == nil {
+		return "", false
+	}
+	key := result.FormatValue(v)
+	if t.cfg.CaseInsensitive {
+		key = strings.ToLower(key)
+	}
+	return key, true
+}
+
+func validate(cfg config.Enrichment) error {
+	if (cfg.Path == "") == (cfg.QueryEngine == "") {
+		return fmt.Errorf("exactly one of path and queryEngine must be set")
+	}
+	if cfg.Field == "" || cfg.Key == "" {
+		return fmt.Errorf("field and key are required")
+	}
+	if len(cfg.Fields) == 0 {
+		return fmt.Errorf("at least one field is required")
+	}
+	for _, f := range cfg.Fields {
+		if f.Field == "" || f.Source == "" {
+			return fmt.Errorf("fields require a field and a source")
+		}
+	}
+	return nil
+}
+
+// loadFile reads a CSV or JSON lookup table, depending on the file extension.
+func loadFile(path string) ([]result.Row, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		header := records[0]
+		rows := make([]result.Row, 0, len(records)-1)
+		for _, record := range records[1:] {
+			row := make(result.Row, len(header))
+			for i, column := range header {
+				row[column] = record[i]
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var objects []map[string]any
+		if err := decoder.Decode(&objects); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		rows := make([]result.Row, len(objects))
+		for i, obj := range objects {
+			rows[i] = result.Row(result.Normalize(obj).(map[string]any))
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unsupported lookup table format %s, expected .csv or .json", filepath.Ext(path))
+	}
+}
@@ -0,0 +1,137 @@
+package enrichment_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/enrichment"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestEnrich(t *testing.T) {
+	rule := &config.RuleConfig{
+		Name: "test-rule",
+		Enrichments: []config.Enrichment{
+			{
+				Path:  "testdata/assets.csv",
+				Field: "device.hostname",
+				Key:   "hostname",
+				Fields: []config.EnrichmentField{
+					{Field: "resource.owner", Source: "owner"},
+					{Field: "resource.criticality", Source: "criticality"},
+				},
+			},
+			{
+				Path:            "testdata/identities.json",
+				Field:           "actor.user.email",
+				Key:             "email",
+				CaseInsensitive: true,
+				Fields: []config.EnrichmentField{
+					{Field: "actor.user.department", Source: "department"},
+					{Field: "actor.user.manager", Source: "manager.email"},
+					{Field: "actor.user.privileged", Source: "privileged"},
+					{Field: "actor.user.level", Source: "level"},
+				},
+			},
+			{
+				QueryEngine: "bigquery.inventory",
+				Language:    "SQL",
+				Query:       "SELECT owner, team FROM owners",
+				Field:       "resource.owner",
+				Key:         "owner",
+				Fields:      []config.EnrichmentField{{Field: "resource.team", Source: "team"}},
+			},
+		},
+	}
+
+	query := func(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error) {
+		if cfg.QueryEngine != "bigquery.inventory" || cfg.Query != "SELECT owner, team FROM owners" || cfg.Name != "test-rule" {
+			t.Errorf("unexpected table query config: %+v", cfg)
+		}
+		return []result.Row{{"owner": "alice", "team": "web"}}, nil
+	}
+
+	e, err := enrichment.New(context.Background(), rule, query)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	rows := []result.Row{
+		{"device": map[string]any{"hostname": "web-1"}, "actor": map[string]any{"user": map[string]any{"email": "alice@example.com"}}},
+		{"device.hostname": "db-1", "actor.user.email": "BOB@example.com"},
+		{"device.hostname": "unknown", "actor.user.email": "eve@example.com"},
+	}
+	e.Enrich(rows)
+
+	expected := []result.Row{
+		{
+			"device": map[string]any{"hostname": "web-1"},
+			"actor": map[string]any{"user": map[string]any{
+				"email":      "alice@example.com",
+				"department": "Security",
+				"manager":    "dana@example.com",
+				"privileged": true,
+				"level":      int64(3),
+			}},
+			"resource": map[string]any{"owner": "alice", "criticality": "high", "team": "web"},
+		},
+		{
+			"device.hostname":  "db-1",
+			"actor.user.email": "BOB@example.com",
+			"actor":            map[string]any{"user": map[string]any{"department": "Finance", "privileged": false, "level": int64(1)}},
+			"resource":         map[string]any{"owner": "bob", "criticality": "critical"},
+		},
+		{"device.hostname": "unknown", "actor.user.email": "eve@example.com"},
+	}
+	if diff := cmp.Diff(expected, rows); diff != "" {
+		t.Errorf("unexpected rows (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		enrichment config.Enrichment
+		errMessage string
+	}{
+		{
+			name:       "no source",
+			enrichment: config.Enrichment{Field: "a", Key: "a", Fields: []config.EnrichmentField{{Field: "b", Source: "b"}}},
+			errMessage: "exactly one of path and queryEngine must be set",
+		},
+		{
+			name:       "no key",
+			enrichment: config.Enrichment{Path: "testdata/assets.csv", Field: "a", Fields: []config.EnrichmentField{{Field: "b", Source: "b"}}},
+			errMessage: "field and key are required",
+		},
+		{
+			name:       "no fields",
+			enrichment: config.Enrichment{Path: "testdata/assets.csv", Field: "a", Key: "a"},
+			errMessage: "at least one field is required",
+		},
+		{
+			name:       "missing file",
+			enrichment: config.Enrichment{Path: "testdata/missing.csv", Field: "a", Key: "a", Fields: []config.EnrichmentField{{Field: "b", Source: "b"}}},
+			errMessage: "enrichment 1: failed to load lookup table",
+		},
+		{
+			name:       "unsupported format",
+			enrichment: config.Enrichment{Path: "enrichment.go", Field: "a", Key: "a", Fields: []config.EnrichmentField{{Field: "b", Source: "b"}}},
+			errMessage: "unsupported lookup table format .go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &config.RuleConfig{Enrichments: []config.Enrichment{tt.enrichment}}
+			_, err := enrichment.New(context.Background(), rule, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
@@ -6,9 +6,15 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
+
+	"github.com/nianticlabs/venator/internal/expr"
+	"github.com/nianticlabs/venator/internal/result"
 )
 
+var logger = logrus.StandardLogger().WithField("pkg", "internal/exclusion")
+
 // Condition represents a single condition in an exclusion rule.
 type Condition struct {
 	Field    string   `yaml:"field"`
@@ -17,9 +23,14 @@ type Condition struct {
 	Values   []string `yaml:"values,omitempty"` // For 'in' and 'not_in' operators
 }
 
-// ExclusionRule represents a single exclusion rule with conditions.
+// ExclusionRule represents a single exclusion rule with conditions or an expression.
 type ExclusionRule struct {
-	Conditions ConditionGroup `yaml:"conditions"`
+	Conditions ConditionGroup `yaml:"conditions,omitempty"`
+	// Expression is a condition in the expression language of the expr package, such as
+	// user.endsWith("@svc") && !(src_ip in cidr("10.0.0.0/8")), used instead of Conditions.
+	Expression string `yaml:"expression,omitempty"`
+
+	program *expr.Program
 }
 
 // ConditionGroup defines logical operators for grouping conditions.
@@ -47,8 +58,20 @@ func NewExcluder(path string) (*Excluder, error) {
 		return nil, fmt.Errorf("failed to decode exclusions YAML: %w", err)
 	}
 
-	// Validate operators and precompile regex patterns
+	// Validate operators and precompile regex patterns and expressions
 	for i, rule := range rules {
+		if rule.Expression != "" {
+			if len(rule.Conditions.And) > 0 || len(rule.Conditions.Or) > 0 {
+				return nil, fmt.Errorf("rule %d sets both conditions and an expression", i+1)
+			}
+			program, err := expr.CompileCondition(rule.Expression, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression in rule %d: %w", i+1, err)
+			}
+			rules[i].program = program
+			continue
+		}
+
 		for _, cond := range rule.Conditions.And {
 			condCopy := cond
 			if err := validateCondition(&condCopy); err != nil {
@@ -90,20 +113,74 @@ func validateCondition(cond *Condition) error {
 
 // IsExcluded checks if a given result matches any exclusion rule.
 // Returns true if excluded, otherwise false.
-func (e *Excluder) IsExcluded(result map[string]string) bool {
-	for _, rule := range e.rules {
-		if evaluateConditionGroup(rule.Conditions, result) {
+func (e *Excluder) IsExcluded(row result.Row) bool {
+	return e.match(row, func(i int, err error) {
+		logger.Debugf("exclusion rule %d: %v", i+1, err)
+	})
+}
+
+// Filter returns the results matching no exclusion rule. Expressions commonly fail to evaluate for
+// some results, e.g. when a field is missing, so failures are logged once per rule.
+func (e *Excluder) Filter(rows []result.Row) []result.Row {
+	failures := make([]int, len(e.rules))
+	lastErrs := make([]error, len(e.rules))
+	var filtered []result.Row
+	for _, row := range rows {
+		excluded := e.match(row, func(i int, err error) {
+			failures[i]++
+			lastErrs[i] = err
+		})
+		if excluded {
+			logger.Debugf("Excluded result: %+v", row)
+			continue
+		}
+		filtered = append(filtered, row)
+	}
+
+	for i, n := range failures {
+		if n > 0 {
+			logger.Warnf("exclusion rule %d: failed to evaluate for %d of %d result(s), last error: %v", i+1, n, len(rows), lastErrs[i])
+		}
+	}
+	return filtered
+}
+
+// match reports whether the result matches any exclusion rule, calling onError with the index of
+// expression rules that fail to evaluate.
+func (e *Excluder) match(row result.Row, onError func(i int, err error)) bool {
+	for i, rule := range e.rules {
+		if rule.program != nil {
+			excluded, err := evaluateExpression(rule.program, row)
+			if err != nil {
+				onError(i, err)
+			}
+			if excluded {
+				return true
+			}
+			continue
+		}
+		if evaluateConditionGroup(rule.Conditions, row) {
 			return true
 		}
 	}
 	return false
 }
 
+// evaluateExpression evaluates an expression against the result. Results the expression fails to
+// evaluate for, e.g. because a field is missing or has an unexpected type, are not excluded.
+func evaluateExpression(program *expr.Program, row result.Row) (bool, error) {
+	excluded, err := program.EvalBool(row)
+	if err != nil {
+		return false, err
+	}
+	return excluded, nil
+}
+
 // evaluateConditionGroup evaluates a group of conditions ("And" or "Or") against the result.
-func evaluateConditionGroup(group ConditionGroup, result map[string]string) bool {
+func evaluateConditionGroup(group ConditionGroup, row result.Row) bool {
 	if len(group.And) > 0 {
 		for _, cond := range group.And {
-			if !evaluateCondition(cond, result) {
+			if !evaluateCondition(cond, row) {
 				return false
 			}
 		}
@@ -112,7 +189,7 @@ func evaluateConditionGroup(group ConditionGroup, result map[string]string) bool
 
 	if len(group.Or) > 0 {
 		for _, cond := range group.Or {
-			if evaluateCondition(cond, result) {
+			if evaluateCondition(cond, row) {
 				return true
 			}
 		}
@@ -124,8 +201,9 @@ func evaluateConditionGroup(group ConditionGroup, result map[string]string) bool
 }
 
 // evaluateCondition evaluates a single condition against the result.
-func evaluateCondition(cond Condition, result map[string]string) bool {
-	value, exists := result[cond.Field]
+// Values are compared in their string form, so numbers and booleans match their YAML spelling.
+func evaluateCondition(cond Condition, row result.Row) bool {
+	value, exists := row.GetString(cond.Field)
 	if !exists {
 		return false
 	}
@@ -1,8 +1,16 @@
 package exclusion
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/nianticlabs/venator/internal/result"
 )
 
 func TestExcluder(t *testing.T) {
@@ -15,12 +23,12 @@ func TestExcluder(t *testing.T) {
 	}
 
 	tests := []struct {
-		result   map[string]string
+		result   result.Row
 		excluded bool
 	}{
 		// Test 'equals' operator with 'and' conditions
 		{
-			result: map[string]string{
+			result: result.Row{
 				"username":   "test",
 				"ip_address": "192.168.1.1",
 			},
@@ -28,7 +36,7 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test 'contains' operator with 'or' conditions
 		{
-			result: map[string]string{
+			result: result.Row{
 				"email":    "user@example.com",
 				"domain":   "external.com",
 				"username": "user1",
@@ -37,7 +45,7 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test 'equals' operator with 'or' conditions
 		{
-			result: map[string]string{
+			result: result.Row{
 				"email":    "user@external.com",
 				"domain":   "internal.local",
 				"username": "user2",
@@ -46,7 +54,7 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test 'equals' operator with 'and' conditions
 		{
-			result: map[string]string{
+			result: result.Row{
 				"response_time": "fast",
 				"status_code":   "200",
 			},
@@ -54,7 +62,7 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test 'equals' operator with 'or' conditions
 		{
-			result: map[string]string{
+			result: result.Row{
 				"user_role": "admin",
 				"username":  "adminuser",
 			},
@@ -62,28 +70,47 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test 'in' operator
 		{
-			result: map[string]string{
+			result: result.Row{
 				"department": "sales",
 			},
 			excluded: true,
 		},
 		// Test 'not_equals' operator
 		{
-			result: map[string]string{
+			result: result.Row{
 				"status": "inactive",
 			},
 			excluded: true,
 		},
 		// Test 'not_in' operator
 		{
-			result: map[string]string{
+			result: result.Row{
 				"region": "eu-west-1",
 			},
 			excluded: true,
 		},
+		// Test nested values addressed by dotted field names
+		{
+			result: result.Row{
+				"actor": map[string]any{"user": map[string]any{"name": "svc-backup"}},
+			},
+			excluded: true,
+		},
+		{
+			result: result.Row{
+				"actor.user.name": "svc-backup",
+			},
+			excluded: true,
+		},
+		{
+			result: result.Row{
+				"actor": map[string]any{"user": map[string]any{"name": "alice"}},
+			},
+			excluded: false,
+		},
 		// Test non-excluded result
 		{
-			result: map[string]string{
+			result: result.Row{
 				"user_role": "user",
 				"username":  "regularuser",
 			},
@@ -91,7 +118,7 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test partial match for 'and' conditions (should not exclude)
 		{
-			result: map[string]string{
+			result: result.Row{
 				"username":      "test",
 				"ip_address":    "10.0.0.1",
 				"response_time": "slow",
@@ -100,36 +127,72 @@ func TestExcluder(t *testing.T) {
 		},
 		// Test 'regex' operator - matching URLs
 		{
-			result: map[string]string{
+			result: result.Row{
 				"url": "https://www.example.com/path",
 			},
 			excluded: true,
 		},
 		{
-			result: map[string]string{
+			result: result.Row{
 				"url": "http://example.com/anotherpath",
 			},
 			excluded: true,
 		},
 		{
-			result: map[string]string{
+			result: result.Row{
 				"url": "https://sub.example.com/path",
 			},
 			excluded: true, // Now should pass with updated regex
 		},
 		// Test 'regex' operator with non-matching URL
 		{
-			result: map[string]string{
+			result: result.Row{
 				"url": "https://www.test.com/path",
 			},
 			excluded: false,
 		},
 		{
-			result: map[string]string{
+			result: result.Row{
 				"url": "ftp://example.com/resource",
 			},
 			excluded: false,
 		},
+		// Test expressions
+		{
+			result: result.Row{
+				"user":   "backup@svc",
+				"src_ip": "203.0.113.7",
+			},
+			excluded: true,
+		},
+		{
+			result: result.Row{
+				"user":   "backup@svc",
+				"src_ip": "10.1.2.3",
+			},
+			excluded: false,
+		},
+		{
+			result: result.Row{
+				"bytes_out": "2000000000",
+				"bucket":    map[string]any{"name": "archive"},
+			},
+			excluded: true,
+		},
+		{
+			result: result.Row{
+				"bytes_out": int64(2000000000),
+			},
+			excluded: false,
+		},
+		// Test expressions that fail to evaluate (should not exclude)
+		{
+			result: result.Row{
+				"user":   int64(42),
+				"src_ip": "203.0.113.7",
+			},
+			excluded: false,
+		},
 	}
 
 	for i, tt := range tests {
@@ -139,3 +202,102 @@ func TestExcluder(t *testing.T) {
 		}
 	}
 }
+
+func TestNewExcluderErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		yaml       string
+		errMessage string
+	}{
+		{
+			name:       "unsupported operator",
+			yaml:       "- conditions:\n    and:\n      - field: user\n        operator: starts_with\n        value: a\n",
+			errMessage: "invalid condition in rule 1: unsupported operator 'starts_with'",
+		},
+		{
+			name:       "expression syntax error",
+			yaml:       "- expression: user ==\n",
+			errMessage: "invalid expression in rule 1",
+		},
+		{
+			name:       "expression type error",
+			yaml:       "- expression: user == 'a'\n- expression: user.endsWith(1)\n",
+			errMessage: "invalid expression in rule 2",
+		},
+		{
+			name:       "expression invalid CIDR",
+			yaml:       "- expression: src_ip in cidr('10.0.0.0/8.1')\n",
+			errMessage: "invalid CIDR",
+		},
+		{
+			name:       "expression not a condition",
+			yaml:       "- expression: user.lowerAscii()\n",
+			errMessage: "expected a bool condition",
+		},
+		{
+			name:       "conditions and expression",
+			yaml:       "- expression: user == 'a'\n  conditions:\n    or:\n      - field: user\n        operator: equals\n        value: a\n",
+			errMessage: "rule 1 sets both conditions and an expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "exclusions.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := NewExcluder(path)
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+func TestFilterLogsEvaluationFailuresOncePerRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exclusions.yaml")
+	yaml := "- expression: bucket.name == 'backups'\n- conditions:\n    and:\n      - field: user\n        operator: equals\n        value: svc\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	excluder, err := NewExcluder(path)
+	if err != nil {
+		t.Fatalf("NewExcluder() unexpected error: %v", err)
+	}
+
+	hook := test.NewLocal(logrus.StandardLogger())
+	defer hook.Reset()
+	rows := []result.Row{
+		{"user": "alice"},
+		{"user": "bob"},
+		{"user": "svc"},
+		{"user": "carol", "bucket": map[string]any{"name": "backups"}},
+	}
+	got := excluder.Filter(rows)
+	if diff := cmp.Diff(rows[:2], got); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+
+	var warnings []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel {
+			warnings = append(warnings, entry.Message)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "exclusion rule 1: failed to evaluate for 3 of 4 result(s)") {
+		t.Errorf("expected one warning for rule 1, got %q", warnings)
+	}
+}
+
+func TestExampleExclusions(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "config", "exclusions", "*.yaml"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no example exclusions found: %v", err)
+	}
+	for _, path := range paths {
+		if _, err := NewExcluder(path); err != nil {
+			t.Errorf("NewExcluder(%s) unexpected error: %v", path, err)
+		}
+	}
+}
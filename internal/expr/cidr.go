@@ -0,0 +1,130 @@
+package expr
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter"
+)
+
+// cidrType is the type of the networks returned by cidr. Networks are containers, so the in
+// operator of the standard library calls their Contains method.
+var cidrType = types.NewObjectType("cidr", traits.ContainerType)
+
+// cidrLibrary declares cidr(string) and the in operator testing whether a string IP address is in a
+// network. Strings that are not IP addresses are in no network.
+func cidrLibrary() cel.EnvOption {
+	return cel.Lib(cidrLib{})
+}
+
+type cidrLib struct{}
+
+func (cidrLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("cidr",
+			cel.Overload("cidr_string", []*cel.Type{cel.StringType}, cidrType,
+				cel.UnaryBinding(func(v ref.Val) ref.Val {
+					c, err := parseCIDR(string(v.(types.String)))
+					if err != nil {
+						return types.WrapErr(err)
+					}
+					return c
+				}))),
+		cel.Function(operators.In,
+			cel.Overload("in_string_cidr", []*cel.Type{cel.StringType, cidrType}, cel.BoolType)),
+	}
+}
+
+func (cidrLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// precompileCIDRs replaces calls of cidr with a constant network by the network, like constant
+// regular expressions are compiled once, and reports invalid networks.
+func precompileCIDRs(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+	call, ok := i.(interpreter.InterpretableCall)
+	if !ok || call.Function() != "cidr" || len(call.Args()) != 1 {
+		return i, nil
+	}
+	arg, ok := call.Args()[0].(interpreter.InterpretableConst)
+	if !ok {
+		return i, nil
+	}
+	s, ok := arg.Value().(types.String)
+	if !ok {
+		return i, nil
+	}
+	c, err := parseCIDR(string(s))
+	if err != nil {
+		return nil, err
+	}
+	return interpreter.NewConstValue(call.ID(), c), nil
+}
+
+func parseCIDR(s string) (cidr, error) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return cidr{}, fmt.Errorf("invalid CIDR %q: %w", s, err)
+	}
+	return cidr{prefix: prefix.Masked()}, nil
+}
+
+// cidr is the CEL value of a network.
+type cidr struct {
+	prefix netip.Prefix
+}
+
+func (c cidr) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	switch typeDesc {
+	case reflect.TypeOf(netip.Prefix{}):
+		return c.prefix, nil
+	case reflect.TypeOf(""):
+		return c.prefix.String(), nil
+	}
+	return nil, fmt.Errorf("type conversion error from cidr to %v", typeDesc)
+}
+
+func (c cidr) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case cidrType:
+		return c
+	case types.StringType:
+		return types.String(c.prefix.String())
+	case types.TypeType:
+		return cidrType
+	}
+	return types.NewErr("type conversion error from cidr to %s", typeVal)
+}
+
+// Contains returns whether the network contains the IP address ip.
+func (c cidr) Contains(ip ref.Val) ref.Val {
+	s, ok := ip.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(ip)
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(string(s)))
+	if err != nil {
+		return types.False
+	}
+	return types.Bool(c.prefix.Contains(addr.Unmap()))
+}
+
+func (c cidr) Equal(other ref.Val) ref.Val {
+	o, ok := other.(cidr)
+	return types.Bool(ok && o.prefix == c.prefix)
+}
+
+func (c cidr) Type() ref.Type {
+	return cidrType
+}
+
+func (c cidr) Value() any {
+	return c.prefix
+}
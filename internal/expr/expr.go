@@ -0,0 +1,208 @@
+// Package expr compiles and evaluates conditions on query results written in the Common Expression
+// Language (CEL), such as double(bytes_out) > 1e9 && actor.user.name in admins.
+//
+// Identifiers and field selections resolve to named variables first, and otherwise to result
+// fields by dotted path, so actor.user.name reads both nested objects and dotted column names.
+// Result fields are dynamically typed: a field missing from a result is an evaluation error, which
+// has() avoids for nested fields, and numeric strings must be converted with int() or double().
+// Numbers of different types compare by value. Fields named like CEL types, such as bytes, cannot
+// be referenced. Besides the CEL standard library and the string extensions such as lowerAscii and
+// trim, cidr returns a network containing the IP addresses in it, e.g. src_ip in cidr("10.0.0.0/8").
+package expr
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+	"github.com/google/cel-go/interpreter"
+
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// Program is a compiled expression.
+type Program struct {
+	src  string
+	prg  cel.Program
+	out  *cel.Type
+	vars map[string]any
+}
+
+// Compile parses the expression and type-checks it against the variables, with result fields
+// declared as dynamically typed. It reports syntax errors and the type errors detectable without
+// results, such as unknown functions, wrong arguments, invalid regular expressions and CIDRs.
+func Compile(src string, vars map[string]any) (*Program, error) {
+	env, err := cel.NewEnv(
+		ext.Strings(),
+		cel.CrossTypeNumericComparisons(true),
+		cidrLibrary(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	parsed, iss := env.Parse(src)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("failed to parse expression %q: %w", src, iss.Err())
+	}
+
+	opts := make([]cel.EnvOption, 0, len(vars))
+	for name, v := range vars {
+		opts = append(opts, cel.Variable(name, varType(v)))
+	}
+	for _, name := range fieldNames(parsed.NativeRep(), vars) {
+		opts = append(opts, cel.Variable(name, cel.DynType))
+	}
+	if env, err = env.Extend(opts...); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	checked, iss := env.Check(parsed)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, iss.Err())
+	}
+
+	// Constant regular expressions and CIDRs are compiled once, failing here if they are invalid.
+	prg, err := env.Program(checked, cel.EvalOptions(cel.OptOptimize), cel.CustomDecorator(precompileCIDRs))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &Program{src: src, prg: prg, out: checked.OutputType(), vars: vars}, nil
+}
+
+// CompileCondition compiles an expression that must evaluate to a bool.
+func CompileCondition(src string, vars map[string]any) (*Program, error) {
+	p, err := Compile(src, vars)
+	if err != nil {
+		return nil, err
+	}
+	if k := p.out.Kind(); k != types.BoolKind && k != types.DynKind {
+		return nil, fmt.Errorf("invalid expression %q: expected a bool condition, got %s", src, p.out)
+	}
+	return p, nil
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.src
+}
+
+// Eval evaluates the expression against a row and returns its value as a Go value.
+func (p *Program) Eval(row result.Row) (any, error) {
+	v, _, err := p.prg.Eval(activation{row: row, vars: p.vars})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression %q: %w", p.src, err)
+	}
+	return native(v)
+}
+
+// EvalBool evaluates a condition against a row.
+func (p *Program) EvalBool(row result.Row) (bool, error) {
+	v, _, err := p.prg.Eval(activation{row: row, vars: p.vars})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate expression %q: %w", p.src, err)
+	}
+	b, ok := v.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q evaluated to %s, expected bool", p.src, v.Type().TypeName())
+	}
+	return bool(b), nil
+}
+
+// activation resolves the variables, then the result fields by dotted path.
+type activation struct {
+	row  result.Row
+	vars map[string]any
+}
+
+func (a activation) ResolveName(name string) (any, bool) {
+	if v, ok := a.vars[name]; ok {
+		return v, true
+	}
+	return a.row.Get(name)
+}
+
+func (a activation) Parent() interpreter.Activation {
+	return nil
+}
+
+// fieldNames returns the identifiers and qualified names of field selections in the expression,
+// e.g. actor, actor.user and actor.user.name, which are declared as result fields. The checker
+// resolves a selection to the longest declared name, and the activation looks it up by path.
+func fieldNames(a *ast.AST, vars map[string]any) []string {
+	seen := make(map[string]bool)
+	local := make(map[string]bool)
+	var names []string
+	ast.PreOrderVisit(a.Expr(), ast.NewExprVisitor(func(e ast.Expr) {
+		if e.Kind() == ast.ComprehensionKind {
+			local[e.AsComprehension().IterVar()] = true
+			local[e.AsComprehension().AccuVar()] = true
+			return
+		}
+		if e.Kind() == ast.SelectKind && e.AsSelect().IsTestOnly() {
+			return
+		}
+		name, ok := qualifiedName(e)
+		if ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}))
+
+	var out []string
+	for _, name := range names {
+		if _, ok := vars[name]; !ok && !local[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// qualifiedName returns the dotted name of an identifier or a selection on one.
+func qualifiedName(e ast.Expr) (string, bool) {
+	switch e.Kind() {
+	case ast.IdentKind:
+		return e.AsIdent(), true
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		if sel.IsTestOnly() {
+			return "", false
+		}
+		operand, ok := qualifiedName(sel.Operand())
+		if !ok {
+			return "", false
+		}
+		return operand + "." + sel.FieldName(), true
+	}
+	return "", false
+}
+
+// varType returns the type variables are declared with: lists of strings, as used for lists of
+// names, or dynamic types.
+func varType(v any) *cel.Type {
+	if _, ok := v.([]string); ok {
+		return cel.ListType(cel.StringType)
+	}
+	return cel.DynType
+}
+
+var (
+	listType = reflect.TypeOf([]any{})
+	mapType  = reflect.TypeOf(map[string]any{})
+)
+
+// native converts a CEL value to the Go value of a result field.
+func native(v ref.Val) (any, error) {
+	switch v := v.(type) {
+	case types.Null:
+		return nil, nil
+	case traits.Lister:
+		return v.ConvertToNative(listType)
+	case traits.Mapper:
+		return v.ConvertToNative(mapType)
+	}
+	return v.Value(), nil
+}
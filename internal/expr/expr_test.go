@@ -0,0 +1,159 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/expr"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestEval(t *testing.T) {
+	row := result.Row{
+		"bytes_out":       "2500000000",
+		"count":           int64(7),
+		"ratio":           0.25,
+		"actor":           map[string]any{"user": map[string]any{"name": "Alice"}},
+		"src_endpoint.ip": "10.0.0.1",
+		"tags":            []any{"vpn", "admin"},
+		"empty":           nil,
+	}
+	vars := map[string]any{"admins": []string{"alice", "bob"}}
+
+	tests := []struct {
+		src      string
+		expected any
+	}{
+		{src: "double(bytes_out) > 1e9", expected: true},
+		{src: "count >= 7 && count < 10", expected: true},
+		{src: "count * 2 + 1", expected: int64(15)},
+		{src: "count / 2", expected: int64(3)},
+		{src: "ratio * 4.0", expected: 1.0},
+		{src: "-count", expected: int64(-7)},
+		{src: "actor.user.name.lowerAscii() in admins", expected: true},
+		{src: "actor.user.name == 'Alice'", expected: true},
+		{src: `src_endpoint.ip.startsWith("10.")`, expected: true},
+		{src: `actor.user.name.matches("^A.*e$")`, expected: true},
+		{src: `"admin" in tags`, expected: true},
+		{src: "tags[1]", expected: "admin"},
+		{src: "actor['user']['name']", expected: "Alice"},
+		{src: "size(tags) == 2 && tags.size() == 2", expected: true},
+		{src: "tags.exists(t, t.startsWith('adm'))", expected: true},
+		{src: "has(actor.user.name) && !has(actor.user.email)", expected: true},
+		{src: "empty == null", expected: true},
+		{src: "int(bytes_out) / 1000000000", expected: int64(2)},
+		{src: "string(count) + '!'", expected: "7!"},
+		{src: "double(count) / 2.0", expected: 3.5},
+		{src: "[1, 2] + [3]", expected: []any{int64(1), int64(2), int64(3)}},
+		{src: "count == 7.0 || 1 / 0 == 1", expected: true},
+		{src: `src_endpoint.ip in cidr("10.0.0.0/8") && !("192.0.2.1" in cidr("10.0.0.0/8"))`, expected: true},
+		{src: `"::ffff:10.1.2.3" in cidr("10.1.0.0/16") && !("not an ip" in cidr("10.0.0.0/8"))`, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := expr.Compile(tt.src, vars)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			got, err := p.Eval(row)
+			if err != nil {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src        string
+		errMessage string
+	}{
+		{src: "count >", errMessage: "failed to parse expression"},
+		{src: "(count > 1", errMessage: "failed to parse expression"},
+		{src: "'unterminated", errMessage: "failed to parse expression"},
+		{src: "lower(name)", errMessage: "undeclared reference to 'lower'"},
+		{src: "name.contains()", errMessage: "found no matching overload for 'contains'"},
+		{src: "name.matches('(')", errMessage: "missing closing )"},
+		{src: "'a' == 1", errMessage: "found no matching overload for '_==_'"},
+		{src: "'a' > 1", errMessage: "found no matching overload for '_>_'"},
+		{src: "true + 1", errMessage: "found no matching overload for '_+_'"},
+		{src: "admins > 1", errMessage: "found no matching overload for '_>_'"},
+		{src: "!1", errMessage: "found no matching overload for '!_'"},
+		{src: "has(name)", errMessage: "invalid argument to has() macro"},
+		{src: `ip in cidr("10.0.0.0/33")`, errMessage: `invalid CIDR "10.0.0.0/33"`},
+		{src: `1 in cidr("10.0.0.0/8")`, errMessage: "found no matching overload for '@in'"},
+		{src: "count && true", errMessage: ""},
+		{src: "name in ['a', 'b'] && tags.all(t, t != name)", errMessage: ""},
+		{src: "'a' + 'b'", errMessage: "expected a bool condition, got string"},
+		{src: "count + 1", errMessage: "expected a bool condition, got int"},
+		{src: "bytes > 1", errMessage: "overlapping identifier for name 'bytes'"},
+	}
+
+	vars := map[string]any{"admins": []string{"alice"}}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := expr.CompileCondition(tt.src, vars)
+			if tt.errMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+func TestEvalBoolErrors(t *testing.T) {
+	tests := []struct {
+		src        string
+		row        result.Row
+		errMessage string
+	}{
+		{src: "name", row: result.Row{"name": "alice"}, errMessage: "evaluated to string, expected bool"},
+		{src: "count + 1 > 2", row: result.Row{"count": "many"}, errMessage: "no such overload"},
+		{src: "flag && true", row: result.Row{"flag": "yes"}, errMessage: "no such overload"},
+		{src: "count / 0 > 1", row: result.Row{"count": int64(1)}, errMessage: "division by zero"},
+		{src: "missing.field > 3", row: result.Row{"count": int64(1)}, errMessage: "no such attribute"},
+		{src: `actor in cidr("10.0.0.0/8")`, row: result.Row{"actor": map[string]any{"ip": "10.0.0.1"}}, errMessage: "no such overload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := expr.CompileCondition(tt.src, nil)
+			if err != nil {
+				t.Fatalf("CompileCondition() unexpected error: %v", err)
+			}
+			_, err = p.EvalBool(tt.row)
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+// TestDocumentedExamples compiles the examples given in the documentation and doc comments.
+func TestDocumentedExamples(t *testing.T) {
+	vars := map[string]any{"admins": []string{"alice"}}
+	for _, src := range []string{
+		"double(bytes_out) > 1e9 && actor.user.name in admins",
+		`src_ip in cidr("10.0.0.0/8")`,
+		"bytes_out > 1e9",
+		`user.endsWith("@svc") && !(src_ip in cidr("10.0.0.0/8"))`,
+		"actor.user.name.lowerAscii().trim() == 'alice'",
+		"count > 1.5",
+		`has(bucket.name) && int(count) > 3`,
+	} {
+		if _, err := expr.CompileCondition(src, vars); err != nil {
+			t.Errorf("CompileCondition(%q) unexpected error: %v", src, err)
+		}
+	}
+}
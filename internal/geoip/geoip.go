@@ -0,0 +1,202 @@
+// Package geoip adds the location, autonomous system and classification of IP addresses to query
+// results, from local MaxMind databases such as GeoLite2.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "internal/geoip")
+
+// Classifications of IP addresses.
+const (
+	ClassPrivate  = "private"
+	ClassPublic   = "public"
+	ClassReserved = "reserved"
+)
+
+// FieldSuffix is appended to an IP field to name the object holding its GeoIP fields, e.g.
+// src_ip_geo.country_iso_code for src_ip.
+const FieldSuffix = "_geo"
+
+// sharedPrefixes are not publicly routable but are used inside networks like private ranges.
+var sharedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+}
+
+// reservedPrefixes are special-purpose ranges that netip.Addr doesn't classify.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// Enricher adds GeoIP fields for the IP fields of a rule.
+type Enricher struct {
+	city   *maxminddb.Reader
+	asn    *maxminddb.Reader
+	fields []string
+}
+
+// New opens the configured databases for the IP fields of the rule. Without databases, only the
+// classification is added.
+func New(global config.GeoIPConfig, rule *config.RuleConfig) (*Enricher, error) {
+	e := &Enricher{fields: rule.GeoIP.Fields}
+	if len(e.fields) == 0 {
+		e.fields = signal.IPSources(rule.Output.Fields)
+	}
+	if len(e.fields) == 0 {
+		return nil, fmt.Errorf("no IP fields: set geoip.fields or map src_endpoint.ip or dst_endpoint.ip")
+	}
+
+	var err error
+	if global.CityDatabase != "" {
+		if e.city, err = maxminddb.Open(global.CityDatabase); err != nil {
+			return nil, fmt.Errorf("failed to open city database: %w", err)
+		}
+	}
+	if global.ASNDatabase != "" {
+		if e.asn, err = maxminddb.Open(global.ASNDatabase); err != nil {
+			e.Close()
+			return nil, fmt.Errorf("failed to open ASN database: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// Close closes the databases opened by New.
+func (e *Enricher) Close() error {
+	var errs []error
+	for _, db := range []*maxminddb.Reader{e.city, e.asn} {
+		if db != nil {
+			errs = append(errs, db.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fields returns the IP fields enriched by e.
+func (e *Enricher) Fields() []string {
+	return e.fields
+}
+
+// Enrich adds an object with the classification and, for public addresses, the country, city,
+// location and autonomous system of every IP field of the rows, named after the field with
+// FieldSuffix. Fields that are missing or not an IP address are skipped.
+func (e *Enricher) Enrich(rows []result.Row) {
+	for _, row := range rows {
+		for _, field := range e.fields {
+			value, ok := row.GetString(field)
+			if !ok {
+				continue
+			}
+			addr, err := netip.ParseAddr(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+			row.Set(field+FieldSuffix, e.lookup(addr))
+		}
+	}
+}
+
+func (e *Enricher) lookup(addr netip.Addr) map[string]any {
+	class := Classify(addr)
+	geo := map[string]any{"classification": class}
+	if class != ClassPublic {
+		return geo
+	}
+
+	if e.city != nil {
+		record, err := lookupRecord(e.city, addr)
+		if err != nil {
+			logger.Warnf("failed to look up %s in the city database: %s", addr, err)
+		}
+		setRecordValue(geo, "country_iso_code", record, "country", "iso_code")
+		setRecordValue(geo, "country_name", record, "country", "names", "en")
+		setRecordValue(geo, "continent_code", record, "continent", "code")
+		setRecordValue(geo, "city_name", record, "city", "names", "en")
+		setRecordValue(geo, "latitude", record, "location", "latitude")
+		setRecordValue(geo, "longitude", record, "location", "longitude")
+	}
+	if e.asn != nil {
+		record, err := lookupRecord(e.asn, addr)
+		if err != nil {
+			logger.Warnf("failed to look up %s in the ASN database: %s", addr, err)
+		}
+		setRecordValue(geo, "asn", record, "autonomous_system_number")
+		setRecordValue(geo, "as_organization", record, "autonomous_system_organization")
+	}
+	return geo
+}
+
+// lookupRecord returns the record of addr, or nil if the database has none. IPv6 addresses have
+// no records in IPv4 databases.
+func lookupRecord(db *maxminddb.Reader, addr netip.Addr) (map[string]any, error) {
+	addr = addr.Unmap()
+	if addr.Is6() && db.Metadata.IPVersion == 4 {
+		return nil, nil
+	}
+	var record map[string]any
+	if err := db.Lookup(addr.AsSlice(), &record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// setRecordValue copies the value at path in a database record, converting it to a row value.
+func setRecordValue(geo map[string]any, key string, record map[string]any, path ...string) {
+	var value any = record
+	for _, p := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		value = m[p]
+	}
+	switch v := value.(type) {
+	case string, float64:
+		geo[key] = v
+	case uint64:
+		geo[key] = int64(v)
+	}
+}
+
+// Classify returns whether addr is private (including carrier-grade NAT), reserved for special
+// purposes (loopback, link-local, multicast, documentation, ...) or public.
+func Classify(addr netip.Addr) string {
+	addr = addr.Unmap()
+	if addr.IsPrivate() {
+		return ClassPrivate
+	}
+	for _, p := range sharedPrefixes {
+		if p.Contains(addr) {
+			return ClassPrivate
+		}
+	}
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsMulticast() || addr.IsUnspecified() ||
+		addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}) {
+		return ClassReserved
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(addr) {
+			return ClassReserved
+		}
+	}
+	return ClassPublic
+}
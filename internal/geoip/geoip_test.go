@@ -0,0 +1,141 @@
+package geoip
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestEnrich(t *testing.T) {
+	cityDB := writeTestDB(t, 6, 28, nil, []testNetwork{{
+		prefix: "8.8.8.0/24",
+		record: map[string]any{
+			"continent": map[string]any{"code": "NA"},
+			"country":   map[string]any{"iso_code": "US", "names": map[string]any{"en": "United States", "de": "USA"}},
+			"city":      map[string]any{"names": map[string]any{"en": "Mountain View"}},
+			"location":  map[string]any{"latitude": 37.386, "longitude": -122.0838},
+		},
+	}})
+	asnDB := writeTestDB(t, 6, 24, nil, []testNetwork{{
+		prefix: "8.8.8.0/24",
+		record: map[string]any{"autonomous_system_number": uint32(15169), "autonomous_system_organization": "GOOGLE"},
+	}})
+	global := config.GeoIPConfig{
+		CityDatabase: writeTestFile(t, "city.mmdb", cityDB),
+		ASNDatabase:  writeTestFile(t, "asn.mmdb", asnDB),
+	}
+	rule := &config.RuleConfig{
+		GeoIP: &config.GeoIP{},
+		Output: config.Output{Fields: []config.OutputField{
+			{Field: "SrcIP", Source: "source.ip"},
+			{Field: "dst_endpoint.ip", Source: "dst_ip"},
+			{Field: "device.ip", Source: "host_ip"},
+		}},
+	}
+
+	e, err := New(global, rule)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := e.Close(); err != nil {
+			t.Errorf("Close() unexpected error: %v", err)
+		}
+	})
+	if diff := cmp.Diff([]string{"source.ip", "dst_ip"}, e.Fields()); diff != "" {
+		t.Errorf("unexpected fields (-want +got):\n%s", diff)
+	}
+
+	rows := []result.Row{
+		{"source": map[string]any{"ip": "8.8.8.8"}, "dst_ip": "10.0.0.1", "host_ip": "8.8.8.8"},
+		{"source.ip": "9.9.9.9", "dst_ip": "not an ip"},
+		{"source.ip": "::1"},
+	}
+	e.Enrich(rows)
+
+	expected := []result.Row{
+		{
+			"source": map[string]any{
+				"ip": "8.8.8.8",
+				"ip_geo": map[string]any{
+					"classification":   ClassPublic,
+					"continent_code":   "NA",
+					"country_iso_code": "US",
+					"country_name":     "United States",
+					"city_name":        "Mountain View",
+					"latitude":         37.386,
+					"longitude":        -122.0838,
+					"asn":              int64(15169),
+					"as_organization":  "GOOGLE",
+				},
+			},
+			"dst_ip":     "10.0.0.1",
+			"dst_ip_geo": map[string]any{"classification": ClassPrivate},
+			"host_ip":    "8.8.8.8",
+		},
+		{
+			"source.ip": "9.9.9.9",
+			"source":    map[string]any{"ip_geo": map[string]any{"classification": ClassPublic}},
+			"dst_ip":    "not an ip",
+		},
+		{
+			"source.ip": "::1",
+			"source":    map[string]any{"ip_geo": map[string]any{"classification": ClassReserved}},
+		},
+	}
+	if diff := cmp.Diff(expected, rows); diff != "" {
+		t.Errorf("unexpected rows (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	rule := &config.RuleConfig{GeoIP: &config.GeoIP{}}
+	if _, err := New(config.GeoIPConfig{}, rule); err == nil || !strings.Contains(err.Error(), "no IP fields") {
+		t.Errorf("expected an error for a rule without IP fields, got %v", err)
+	}
+
+	rule.GeoIP.Fields = []string{"ip"}
+	_, err := New(config.GeoIPConfig{ASNDatabase: "testdata/missing.mmdb"}, rule)
+	if err == nil || !strings.Contains(err.Error(), "failed to open ASN database") {
+		t.Errorf("expected an error for a missing database, got %v", err)
+	}
+
+	invalid := writeTestFile(t, "invalid.mmdb", []byte("not a database"))
+	_, err = New(config.GeoIPConfig{CityDatabase: invalid}, rule)
+	if err == nil || !strings.Contains(err.Error(), "failed to open city database") {
+		t.Errorf("expected an error for an invalid database, got %v", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]string{
+		"10.1.2.3":        ClassPrivate,
+		"172.16.0.1":      ClassPrivate,
+		"192.168.1.1":     ClassPrivate,
+		"100.64.0.1":      ClassPrivate,
+		"fd00::1":         ClassPrivate,
+		"::ffff:10.0.0.1": ClassPrivate,
+		"127.0.0.1":       ClassReserved,
+		"169.254.1.1":     ClassReserved,
+		"224.0.0.1":       ClassReserved,
+		"0.0.0.0":         ClassReserved,
+		"255.255.255.255": ClassReserved,
+		"192.0.2.10":      ClassReserved,
+		"203.0.113.5":     ClassReserved,
+		"240.0.0.1":       ClassReserved,
+		"fe80::1":         ClassReserved,
+		"2001:db8::1":     ClassReserved,
+		"8.8.8.8":         ClassPublic,
+		"2606:4700::1111": ClassPublic,
+	}
+	for addr, expected := range tests {
+		if got := Classify(netip.MustParseAddr(addr)); got != expected {
+			t.Errorf("Classify(%s) = %s, want %s", addr, got, expected)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// metadataMarker precedes the metadata map at the end of MaxMind DB files.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSectionSeparator is the size of the zeroed separator between the search tree and the data.
+const dataSectionSeparator = 16
+
+// Data types of the MaxMind DB format, see https://maxmind.github.io/MaxMind-DB/.
+const (
+	typePointer = 1
+	typeString  = 2
+	typeDouble  = 3
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+	typeArray   = 11
+	typeBool    = 14
+)
+
+// testPointer is encoded as a pointer into the data section, to share values between records.
+type testPointer uint
+
+// testNetwork is a network of a test database and its record.
+type testNetwork struct {
+	prefix string
+	record map[string]any
+}
+
+// writeTestDB builds a MaxMind DB holding networks. The shared value is written first, at offset
+// 0 of the data section, so records can reference it with testPointer(0).
+func writeTestDB(t *testing.T, ipVersion, recordSize int, shared any, networks []testNetwork) []byte {
+	t.Helper()
+
+	type record struct {
+		node int // index of the child node, or -1
+		data int // index of the network record, or -1
+	}
+	nodes := [][2]record{{{-1, -1}, {-1, -1}}}
+	for i, n := range networks {
+		prefix := netip.MustParsePrefix(n.prefix)
+		var bits []byte
+		length := prefix.Bits()
+		switch {
+		case ipVersion == 6 && prefix.Addr().Is4():
+			// IPv4 networks are stored below ::/96.
+			b := prefix.Addr().As4()
+			bits = append(make([]byte, 12), b[:]...)
+			length += 96
+		case ipVersion == 6:
+			b := prefix.Addr().As16()
+			bits = b[:]
+		default:
+			b := prefix.Addr().As4()
+			bits = b[:]
+		}
+
+		node := 0
+		for bit := 0; bit < length; bit++ {
+			side := bits[bit/8] >> (7 - bit%8) & 1
+			if bit == length-1 {
+				nodes[node][side] = record{node: -1, data: i}
+				break
+			}
+			if nodes[node][side].node < 0 {
+				nodes = append(nodes, [2]record{{-1, -1}, {-1, -1}})
+				nodes[node][side] = record{node: len(nodes) - 1, data: -1}
+			}
+			node = nodes[node][side].node
+		}
+	}
+
+	var data []byte
+	if shared != nil {
+		data = encodeTestValue(t, data, shared)
+	}
+	offsets := make([]int, len(networks))
+	for i, n := range networks {
+		offsets[i] = len(data)
+		data = encodeTestValue(t, data, n.record)
+	}
+
+	nodeCount := len(nodes)
+	value := func(r record) uint32 {
+		switch {
+		case r.node >= 0:
+			return uint32(r.node)
+		case r.data >= 0:
+			return uint32(nodeCount + dataSectionSeparator + offsets[r.data])
+		default:
+			return uint32(nodeCount)
+		}
+	}
+
+	var buf []byte
+	for _, n := range nodes {
+		left, right := value(n[0]), value(n[1])
+		switch recordSize {
+		case 24:
+			buf = append(buf, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			buf = append(buf, byte(left>>16), byte(left>>8), byte(left), byte(left>>24&0xf)<<4|byte(right>>24&0xf), byte(right>>16), byte(right>>8), byte(right))
+		default:
+			buf = binary.BigEndian.AppendUint32(buf, left)
+			buf = binary.BigEndian.AppendUint32(buf, right)
+		}
+	}
+	buf = append(buf, make([]byte, dataSectionSeparator)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	return encodeTestValue(t, buf, map[string]any{
+		"node_count":    uint32(nodeCount),
+		"record_size":   uint16(recordSize),
+		"ip_version":    uint16(ipVersion),
+		"database_type": "Test",
+		"description":   map[string]any{"en": "Venator test database"},
+		"languages":     []any{"en"},
+
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+	})
+}
+
+func encodeTestValue(t *testing.T, buf []byte, v any) []byte {
+	t.Helper()
+	control := func(typ int, size int) []byte {
+		var b []byte
+		ctrl := byte(0)
+		if typ > 7 {
+			b = append(b, byte(typ-7))
+		} else {
+			ctrl = byte(typ) << 5
+		}
+		switch {
+		case size < 29:
+			ctrl |= byte(size)
+		case size < 285:
+			ctrl |= 29
+			b = append(b, byte(size-29))
+		default:
+			t.Fatalf("test values are limited to 284 bytes")
+		}
+		return append([]byte{ctrl}, b...)
+	}
+	uintBytes := func(u uint64) []byte {
+		b := binary.BigEndian.AppendUint64(nil, u)
+		for len(b) > 0 && b[0] == 0 {
+			b = b[1:]
+		}
+		return b
+	}
+
+	switch val := v.(type) {
+	case testPointer:
+		if val >= 2048 {
+			t.Fatalf("test pointers are limited to 11 bits")
+		}
+		return append(buf, byte(typePointer)<<5|byte(val>>8), byte(val))
+	case string:
+		buf = append(buf, control(typeString, len(val))...)
+		return append(buf, val...)
+	case float64:
+		buf = append(buf, control(typeDouble, 8)...)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(val))
+	case uint16:
+		b := uintBytes(uint64(val))
+		return append(append(buf, control(typeUint16, len(b))...), b...)
+	case uint32:
+		b := uintBytes(uint64(val))
+		return append(append(buf, control(typeUint32, len(b))...), b...)
+	case bool:
+		size := 0
+		if val {
+			size = 1
+		}
+		return append(buf, control(typeBool, size)...)
+	case []any:
+		buf = append(buf, control(typeArray, len(val))...)
+		for _, item := range val {
+			buf = encodeTestValue(t, buf, item)
+		}
+		return buf
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = append(buf, control(typeMap, len(val))...)
+		for _, k := range keys {
+			buf = encodeTestValue(t, buf, k)
+			buf = encodeTestValue(t, buf, val[k])
+		}
+		return buf
+	default:
+		t.Fatalf("unsupported test value %T", v)
+		return nil
+	}
+}
+
+func writeTestFile(t *testing.T, name string, db []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, db, 0o600); err != nil {
+		t.Fatalf("failed to write test database: %v", err)
+	}
+	return path
+}
+
+// TestWriteTestDB checks the test databases against the MaxMind DB reader, for every IP version and
+// record size.
+func TestWriteTestDB(t *testing.T) {
+	shared := map[string]any{"iso_code": "US"}
+	networks := []testNetwork{
+		{prefix: "8.8.8.0/24", record: map[string]any{"country": testPointer(0), "anycast": true}},
+		{prefix: "1.1.1.1/32", record: map[string]any{"names": []any{"one", "one.one"}, "score": 0.5}},
+		{prefix: "2001:4860::/32", record: map[string]any{"asn": uint32(15169)}},
+	}
+
+	tests := []struct {
+		addr     string
+		expected map[string]any
+	}{
+		{addr: "8.8.8.8", expected: map[string]any{"country": map[string]any{"iso_code": "US"}, "anycast": true}},
+		{addr: "::ffff:8.8.8.9", expected: map[string]any{"country": map[string]any{"iso_code": "US"}, "anycast": true}},
+		{addr: "8.8.4.4"},
+		{addr: "1.1.1.1", expected: map[string]any{"names": []any{"one", "one.one"}, "score": 0.5}},
+		{addr: "1.1.1.2"},
+		{addr: "2001:4860:4860::8888", expected: map[string]any{"asn": uint64(15169)}},
+	}
+
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			testNetworks := networks
+			if ipVersion == 4 {
+				testNetworks = networks[:2]
+			}
+			db, err := maxminddb.FromBytes(writeTestDB(t, ipVersion, recordSize, shared, testNetworks))
+			if err != nil {
+				t.Fatalf("IPv%d/%d: FromBytes() unexpected error: %v", ipVersion, recordSize, err)
+			}
+			for _, tt := range tests {
+				addr := netip.MustParseAddr(tt.addr)
+				expected := tt.expected
+				if ipVersion == 4 && addr.Is6() && !addr.Is4In6() {
+					expected = nil
+				}
+				got, err := lookupRecord(db, addr)
+				if err != nil {
+					t.Fatalf("IPv%d/%d: lookupRecord(%s) unexpected error: %v", ipVersion, recordSize, tt.addr, err)
+				}
+				if diff := cmp.Diff(expected, got); diff != "" {
+					t.Errorf("IPv%d/%d: lookupRecord(%s) unexpected record (-want +got):\n%s", ipVersion, recordSize, tt.addr, diff)
+				}
+			}
+		}
+	}
+}
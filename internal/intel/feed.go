@@ -0,0 +1,275 @@
+package intel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+)
+
+// Indicator types.
+const (
+	TypeIP     = "ip"
+	TypeCIDR   = "cidr"
+	TypeDomain = "domain"
+	TypeHash   = "hash"
+	TypeURL    = "url"
+)
+
+// Feed formats.
+const (
+	FormatPlain = "plain"
+	FormatCSV   = "csv"
+	FormatSTIX  = "stix"
+)
+
+var hashPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$|^[0-9a-fA-F]{40}$|^[0-9a-fA-F]{64}$|^[0-9a-fA-F]{128}$`)
+
+// stixComparison matches the equality comparisons of STIX patterns, e.g.
+// [file:hashes.'SHA-256' = '...'], capturing the object type, the property path and the value.
+var stixComparison = regexp.MustCompile(`([a-z0-9-]+):((?:[A-Za-z0-9_-]+|'[^']*')(?:\.(?:[A-Za-z0-9_-]+|'[^']*'))*)\s*=\s*'((?:[^'\\]|\\.)*)'`)
+
+// Indicator is a normalized indicator of a feed.
+type Indicator struct {
+	Value      string
+	Type       string
+	Confidence int
+}
+
+// feed holds the indicators of a feed file, indexed for matching.
+type feed struct {
+	name string
+	cfg  config.IntelFeed
+	// values indexes IP, domain, hash and URL indicators by indexKey.
+	values map[string]Indicator
+	cidrs  []cidrIndicator
+}
+
+type cidrIndicator struct {
+	prefix netip.Prefix
+	Indicator
+}
+
+func indexKey(typ, value string) string {
+	return typ + "\x00" + value
+}
+
+// load reads the feed file, dropping STIX indicators that expired before now.
+func (f *feed) load(now time.Time) error {
+	data, err := os.ReadFile(f.cfg.Path)
+	if err != nil {
+		return err
+	}
+	var indicators []Indicator
+	switch f.cfg.Format {
+	case FormatPlain:
+		indicators, err = parsePlain(data, f.cfg)
+	case FormatCSV:
+		indicators, err = parseCSV(data, f.cfg)
+	case FormatSTIX:
+		indicators, err = parseSTIX(data, f.cfg, now)
+	default:
+		err = fmt.Errorf("unsupported feed format '%s'", f.cfg.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.values = make(map[string]Indicator)
+	for _, ind := range indicators {
+		if ind.Type == TypeCIDR {
+			f.cidrs = append(f.cidrs, cidrIndicator{prefix: netip.MustParsePrefix(ind.Value), Indicator: ind})
+			continue
+		}
+		f.values[indexKey(ind.Type, ind.Value)] = ind
+	}
+	logger.Infof("loaded %d indicators from feed %s", len(indicators), f.name)
+	return nil
+}
+
+// parsePlain reads one indicator per line, ignoring blank lines and # comments.
+func parsePlain(data []byte, cfg config.IntelFeed) ([]Indicator, error) {
+	var indicators []Indicator
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		ind, err := newIndicator(value, cfg.Type, cfg.Confidence)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		indicators = append(indicators, ind)
+	}
+	return indicators, scanner.Err()
+}
+
+// parseCSV reads indicators from a CSV file with a header row.
+func parseCSV(data []byte, cfg config.IntelFeed) ([]Indicator, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	column := func(name, fallback string) int {
+		if name == "" {
+			name = fallback
+		}
+		for i, h := range records[0] {
+			if strings.TrimSpace(h) == name {
+				return i
+			}
+		}
+		return -1
+	}
+	indicatorCol := column(cfg.IndicatorColumn, "indicator")
+	typeCol := column(cfg.TypeColumn, "type")
+	confidenceCol := column(cfg.ConfidenceColumn, "confidence")
+	if indicatorCol < 0 {
+		return nil, fmt.Errorf("indicator column not found in CSV header")
+	}
+
+	var indicators []Indicator
+	for i, record := range records[1:] {
+		typ, confidence := cfg.Type, cfg.Confidence
+		if typeCol >= 0 && strings.TrimSpace(record[typeCol]) != "" {
+			typ = strings.ToLower(strings.TrimSpace(record[typeCol]))
+		}
+		if confidenceCol >= 0 && strings.TrimSpace(record[confidenceCol]) != "" {
+			if confidence, err = strconv.Atoi(strings.TrimSpace(record[confidenceCol])); err != nil {
+				return nil, fmt.Errorf("row %d: invalid confidence: %w", i+2, err)
+			}
+		}
+		ind, err := newIndicator(strings.TrimSpace(record[indicatorCol]), typ, confidence)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		indicators = append(indicators, ind)
+	}
+	return indicators, nil
+}
+
+// parseSTIX reads the equality comparisons of the patterns of the indicators of a STIX 2.1 bundle.
+// Revoked and expired indicators are skipped.
+func parseSTIX(data []byte, cfg config.IntelFeed, now time.Time) ([]Indicator, error) {
+	var bundle struct {
+		Objects []struct {
+			Type       string     `json:"type"`
+			Pattern    string     `json:"pattern"`
+			Confidence *int       `json:"confidence"`
+			Revoked    bool       `json:"revoked"`
+			ValidUntil *time.Time `json:"valid_until"`
+		} `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse STIX bundle: %w", err)
+	}
+
+	var indicators []Indicator
+	for _, obj := range bundle.Objects {
+		if obj.Type != "indicator" || obj.Revoked || obj.ValidUntil != nil && !now.Before(*obj.ValidUntil) {
+			continue
+		}
+		confidence := cfg.Confidence
+		if obj.Confidence != nil {
+			confidence = *obj.Confidence
+		}
+		for _, m := range stixComparison.FindAllStringSubmatch(obj.Pattern, -1) {
+			var typ string
+			switch {
+			case (m[1] == "ipv4-addr" || m[1] == "ipv6-addr") && m[2] == "value":
+				// Detected, since address values may be CIDRs.
+			case m[1] == "domain-name" && m[2] == "value":
+				typ = TypeDomain
+			case m[1] == "url" && m[2] == "value":
+				typ = TypeURL
+			case m[1] == "file" && strings.HasPrefix(m[2], "hashes."):
+				typ = TypeHash
+			default:
+				continue
+			}
+			value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(m[3])
+			ind, err := newIndicator(value, typ, confidence)
+			if err != nil {
+				return nil, fmt.Errorf("pattern %q: %w", obj.Pattern, err)
+			}
+			indicators = append(indicators, ind)
+		}
+	}
+	return indicators, nil
+}
+
+// newIndicator validates and normalizes an indicator, detecting its type if typ is empty.
+func newIndicator(value, typ string, confidence int) (Indicator, error) {
+	if typ == "" {
+		typ = detectType(value)
+	}
+	normalized, ok := normalize(value, typ)
+	if !ok {
+		return Indicator{}, fmt.Errorf("invalid %s indicator %q", typ, value)
+	}
+	return Indicator{Value: normalized, Type: typ, Confidence: confidence}, nil
+}
+
+func detectType(value string) string {
+	if _, err := netip.ParseAddr(value); err == nil {
+		return TypeIP
+	}
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return TypeCIDR
+	}
+	if hashPattern.MatchString(value) {
+		return TypeHash
+	}
+	if strings.Contains(value, "://") {
+		return TypeURL
+	}
+	return TypeDomain
+}
+
+// normalize returns the form indicators and result values are compared in.
+func normalize(value, typ string) (string, bool) {
+	switch typ {
+	case TypeIP:
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", false
+		}
+		return addr.Unmap().String(), true
+	case TypeCIDR:
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return "", false
+		}
+		return prefix.Masked().String(), true
+	case TypeDomain:
+		domain := strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(value), "."), "*.")
+		return domain, domain != "" && !strings.ContainsAny(domain, " /:")
+	case TypeHash:
+		return strings.ToLower(value), hashPattern.MatchString(value)
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" {
+			return "", false
+		}
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		return strings.TrimSuffix(u.String(), "/"), true
+	default:
+		return "", false
+	}
+}
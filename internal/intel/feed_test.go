@@ -0,0 +1,119 @@
+package intel
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+)
+
+func TestParseFeeds(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		path     string
+		parse    func([]byte, config.IntelFeed) ([]Indicator, error)
+		cfg      config.IntelFeed
+		expected []Indicator
+	}{
+		{
+			name:  "plain",
+			path:  "testdata/plain.txt",
+			parse: parsePlain,
+			cfg:   config.IntelFeed{Confidence: 60},
+			expected: []Indicator{
+				{Value: "203.0.113.7", Type: TypeIP, Confidence: 60},
+				{Value: "198.51.100.0/24", Type: TypeCIDR, Confidence: 60},
+				{Value: "evil.example", Type: TypeDomain, Confidence: 60},
+				{Value: "44d88612fea8a8f36de82e1278abb02f", Type: TypeHash, Confidence: 60},
+				{Value: "https://phish.example.net/login", Type: TypeURL, Confidence: 60},
+			},
+		},
+		{
+			name:  "csv",
+			path:  "testdata/indicators.csv",
+			parse: parseCSV,
+			cfg:   config.IntelFeed{Confidence: 10},
+			expected: []Indicator{
+				{Value: "192.0.2.1", Type: TypeIP, Confidence: 90},
+				{Value: "malware.example.org", Type: TypeDomain, Confidence: 40},
+				{Value: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Type: TypeHash, Confidence: 10},
+			},
+		},
+		{
+			name: "stix",
+			path: "testdata/bundle.json",
+			parse: func(data []byte, cfg config.IntelFeed) ([]Indicator, error) {
+				return parseSTIX(data, cfg, now)
+			},
+			cfg: config.IntelFeed{Confidence: 50},
+			expected: []Indicator{
+				{Value: "203.0.113.0/28", Type: TypeCIDR, Confidence: 85},
+				{Value: "c2.example.com", Type: TypeDomain, Confidence: 85},
+				{Value: "aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899", Type: TypeHash, Confidence: 50},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("failed to read feed: %v", err)
+			}
+			got, err := tt.parse(data, tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected indicators (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		parse      func() error
+		errMessage string
+	}{
+		{
+			name: "invalid typed indicator",
+			parse: func() error {
+				_, err := parsePlain([]byte("# header\nnot-an-ip\n"), config.IntelFeed{Type: TypeIP})
+				return err
+			},
+			errMessage: `line 2: invalid ip indicator "not-an-ip"`,
+		},
+		{
+			name: "missing indicator column",
+			parse: func() error {
+				_, err := parseCSV([]byte("value\n1.2.3.4\n"), config.IntelFeed{})
+				return err
+			},
+			errMessage: "indicator column not found in CSV header",
+		},
+		{
+			name: "invalid confidence",
+			parse: func() error {
+				_, err := parseCSV([]byte("ioc,score\n1.2.3.4,high\n"), config.IntelFeed{IndicatorColumn: "ioc", ConfidenceColumn: "score"})
+				return err
+			},
+			errMessage: "row 2: invalid confidence",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse()
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
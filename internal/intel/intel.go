@@ -0,0 +1,206 @@
+// Package intel matches query results against threat-intel indicator feeds.
+package intel
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "internal/intel")
+
+// Field is the result field holding the matches of a result: the indicator, type, feed and
+// confidence of the match with the highest confidence, and all matches below matches.
+const Field = "intel"
+
+// Match is an indicator matching a result field.
+type Match struct {
+	Indicator
+	Feed  string
+	Field string
+}
+
+// Matcher matches results against the feeds of a rule.
+type Matcher struct {
+	rule  *config.RuleConfig
+	feeds []*feed
+}
+
+// New loads the feeds of the rule from the global intel config.
+func New(global config.IntelConfig, rule *config.RuleConfig) (*Matcher, error) {
+	cfg := rule.Intel
+	if len(cfg.Fields) == 0 {
+		return nil, fmt.Errorf("intel requires at least one field")
+	}
+	switch cfg.Confidence {
+	case "", config.ConfidenceLow, config.ConfidenceMedium, config.ConfidenceHigh:
+	default:
+		return nil, fmt.Errorf("unsupported intel confidence '%s'", cfg.Confidence)
+	}
+
+	names := cfg.Feeds
+	if len(names) == 0 {
+		for name := range global.Feeds {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no intel feeds configured")
+	}
+
+	m := &Matcher{rule: rule}
+	now := time.Now()
+	for _, name := range names {
+		feedCfg, ok := global.Feeds[name]
+		if !ok {
+			return nil, fmt.Errorf("intel feed '%s' not found", name)
+		}
+		f := &feed{name: name, cfg: feedCfg}
+		if err := f.load(now); err != nil {
+			return nil, fmt.Errorf("failed to load intel feed '%s': %w", name, err)
+		}
+		m.feeds = append(m.feeds, f)
+	}
+	return m, nil
+}
+
+// Match tags the rows with an indicator match, raises their confidence if the rule sets one and,
+// if the rule requires a match, drops the other rows.
+func (m *Matcher) Match(rows []result.Row) []result.Row {
+	cfg := m.rule.Intel
+	var out []result.Row
+	matched := 0
+	for _, row := range rows {
+		matches := m.matchRow(row)
+		if len(matches) == 0 {
+			if !cfg.Require {
+				out = append(out, row)
+			}
+			continue
+		}
+
+		matched++
+		row.Set(Field, tag(matches))
+		if cfg.Confidence != "" {
+			signal.RaiseConfidence(row, m.rule, cfg.Confidence)
+		}
+		out = append(out, row)
+	}
+	logger.Infof("rule %s: %d of %d result(s) matched an indicator", m.rule.Name, matched, len(rows))
+	return out
+}
+
+// matchRow returns the matches of the fields of row, ordered by decreasing confidence.
+func (m *Matcher) matchRow(row result.Row) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+	for _, field := range m.rule.Intel.Fields {
+		value, ok := row.Get(field)
+		if !ok || value == nil {
+			continue
+		}
+		values := []any{value}
+		if list, ok := value.([]any); ok {
+			values = list
+		}
+		for _, v := range values {
+			for _, f := range m.feeds {
+				for _, ind := range f.match(strings.TrimSpace(result.FormatValue(v))) {
+					key := f.name + "\x00" + field + "\x00" + indexKey(ind.Type, ind.Value)
+					if ind.Confidence < m.rule.Intel.MinConfidence || seen[key] {
+						continue
+					}
+					seen[key] = true
+					matches = append(matches, Match{Indicator: ind, Feed: f.name, Field: field})
+				}
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+	return matches
+}
+
+// match returns the indicators of the feed matching value: IPs and the CIDRs containing them,
+// hashes, domains and their parent domains, and URLs, whose host is matched as well.
+func (f *feed) match(value string) []Indicator {
+	if value == "" {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(value); err == nil {
+		addr = addr.Unmap()
+		var out []Indicator
+		if ind, ok := f.values[indexKey(TypeIP, addr.String())]; ok {
+			out = append(out, ind)
+		}
+		for _, c := range f.cidrs {
+			if c.prefix.Contains(addr) {
+				out = append(out, c.Indicator)
+			}
+		}
+		return out
+	}
+
+	if strings.Contains(value, "://") {
+		var out []Indicator
+		if normalized, ok := normalize(value, TypeURL); ok {
+			if ind, ok := f.values[indexKey(TypeURL, normalized)]; ok {
+				out = append(out, ind)
+			}
+		}
+		if u, err := url.Parse(value); err == nil && u.Hostname() != "" {
+			out = append(out, f.match(u.Hostname())...)
+		}
+		return out
+	}
+
+	lower := strings.ToLower(value)
+	if hashPattern.MatchString(value) {
+		if ind, ok := f.values[indexKey(TypeHash, lower)]; ok {
+			return []Indicator{ind}
+		}
+	}
+	var out []Indicator
+	for domain := strings.TrimSuffix(lower, "."); domain != ""; {
+		if ind, ok := f.values[indexKey(TypeDomain, domain)]; ok {
+			out = append(out, ind)
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return out
+}
+
+// tag returns the intel field of a result with matches.
+func tag(matches []Match) map[string]any {
+	all := make([]any, len(matches))
+	for i, m := range matches {
+		all[i] = map[string]any{
+			"indicator":  m.Value,
+			"type":       m.Type,
+			"feed":       m.Feed,
+			"field":      m.Field,
+			"confidence": int64(m.Confidence),
+		}
+	}
+	best := matches[0]
+	return map[string]any{
+		"indicator":  best.Value,
+		"type":       best.Type,
+		"feed":       best.Feed,
+		"confidence": int64(best.Confidence),
+		"matches":    all,
+	}
+}
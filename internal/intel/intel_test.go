@@ -0,0 +1,169 @@
+package intel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func testFeeds() config.IntelConfig {
+	return config.IntelConfig{Feeds: map[string]config.IntelFeed{
+		"blocklist": {Path: "testdata/plain.txt", Format: FormatPlain, Confidence: 60},
+		"vendor":    {Path: "testdata/indicators.csv", Format: FormatCSV},
+		"sharing":   {Path: "testdata/bundle.json", Format: FormatSTIX},
+	}}
+}
+
+func TestMatch(t *testing.T) {
+	rule := &config.RuleConfig{
+		Name:       "test-rule",
+		Confidence: config.ConfidenceLow,
+		Intel: &config.Intel{
+			Fields:     []string{"src_ip", "dns.query", "url", "file.hashes"},
+			Confidence: config.ConfidenceHigh,
+		},
+	}
+	m, err := New(testFeeds(), rule)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	rows := []result.Row{
+		{"src_ip": "203.0.113.7"},
+		{"dns": map[string]any{"query": "www.Evil.example."}, "url": "https://phish.example.net/login/"},
+		{"file": map[string]any{"hashes": []any{"0000", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"}}},
+		{"url": "http://c2.example.com:8080/beacon"},
+		{"src_ip": "10.0.0.1"},
+	}
+	got := m.Match(rows)
+
+	expected := []result.Row{
+		{
+			"src_ip": "203.0.113.7",
+			"intel": map[string]any{
+				"indicator": "203.0.113.0/28", "type": TypeCIDR, "feed": "sharing", "confidence": int64(85),
+				"matches": []any{
+					map[string]any{"indicator": "203.0.113.0/28", "type": TypeCIDR, "feed": "sharing", "field": "src_ip", "confidence": int64(85)},
+					map[string]any{"indicator": "203.0.113.7", "type": TypeIP, "feed": "blocklist", "field": "src_ip", "confidence": int64(60)},
+				},
+			},
+			"venator": map[string]any{"confidence": "high"},
+		},
+		{
+			"dns": map[string]any{"query": "www.Evil.example."},
+			"url": "https://phish.example.net/login/",
+			"intel": map[string]any{
+				"indicator": "evil.example", "type": TypeDomain, "feed": "blocklist", "confidence": int64(60),
+				"matches": []any{
+					map[string]any{"indicator": "evil.example", "type": TypeDomain, "feed": "blocklist", "field": "dns.query", "confidence": int64(60)},
+					map[string]any{"indicator": "https://phish.example.net/login", "type": TypeURL, "feed": "blocklist", "field": "url", "confidence": int64(60)},
+				},
+			},
+			"venator": map[string]any{"confidence": "high"},
+		},
+		{
+			"file": map[string]any{"hashes": []any{"0000", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"}},
+			"intel": map[string]any{
+				"indicator": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "type": TypeHash, "feed": "vendor", "confidence": int64(0),
+				"matches": []any{
+					map[string]any{"indicator": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "type": TypeHash, "feed": "vendor", "field": "file.hashes", "confidence": int64(0)},
+				},
+			},
+			"venator": map[string]any{"confidence": "high"},
+		},
+		{
+			"url": "http://c2.example.com:8080/beacon",
+			"intel": map[string]any{
+				"indicator": "c2.example.com", "type": TypeDomain, "feed": "sharing", "confidence": int64(85),
+				"matches": []any{
+					map[string]any{"indicator": "c2.example.com", "type": TypeDomain, "feed": "sharing", "field": "url", "confidence": int64(85)},
+				},
+			},
+			"venator": map[string]any{"confidence": "high"},
+		},
+		{"src_ip": "10.0.0.1"},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected rows (-want +got):\n%s", diff)
+	}
+	if confidence := signal.FindingConfidence(got[4], rule); confidence != config.ConfidenceLow {
+		t.Errorf("expected the confidence of rows without a match to be kept, got %s", confidence)
+	}
+}
+
+func TestMatchRequire(t *testing.T) {
+	rule := &config.RuleConfig{
+		Intel: &config.Intel{
+			Feeds:         []string{"vendor"},
+			Fields:        []string{"ip", "domain"},
+			MinConfidence: 50,
+			Require:       true,
+		},
+	}
+	m, err := New(testFeeds(), rule)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	got := m.Match([]result.Row{{"ip": "192.0.2.1"}, {"domain": "malware.example.org"}, {"ip": "203.0.113.7"}})
+	if len(got) != 1 || got[0]["ip"] != "192.0.2.1" {
+		t.Fatalf("expected only the match above the minimum confidence, got %v", got)
+	}
+	if _, ok := got[0].Get(signal.ConfidenceField); ok {
+		t.Errorf("expected the confidence to be kept without intel confidence, got %v", got[0])
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		global     config.IntelConfig
+		intel      config.Intel
+		errMessage string
+	}{
+		{
+			name:       "no fields",
+			global:     testFeeds(),
+			errMessage: "intel requires at least one field",
+		},
+		{
+			name:       "unknown feed",
+			global:     testFeeds(),
+			intel:      config.Intel{Fields: []string{"ip"}, Feeds: []string{"missing"}},
+			errMessage: "intel feed 'missing' not found",
+		},
+		{
+			name:       "no feeds",
+			intel:      config.Intel{Fields: []string{"ip"}},
+			errMessage: "no intel feeds configured",
+		},
+		{
+			name:       "invalid confidence",
+			global:     testFeeds(),
+			intel:      config.Intel{Fields: []string{"ip"}, Confidence: "critical"},
+			errMessage: "unsupported intel confidence 'critical'",
+		},
+		{
+			name: "unsupported format",
+			global: config.IntelConfig{Feeds: map[string]config.IntelFeed{
+				"feed": {Path: "testdata/plain.txt", Format: "misp"},
+			}},
+			intel:      config.Intel{Fields: []string{"ip"}},
+			errMessage: "failed to load intel feed 'feed': unsupported feed format 'misp'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.global, &config.RuleConfig{Intel: &tt.intel})
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
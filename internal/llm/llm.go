@@ -14,6 +14,7 @@ import (
 	llmconfig "github.com/nianticlabs/venator/internal/llm/config"
 	"github.com/nianticlabs/venator/internal/llm/model"
 	"github.com/nianticlabs/venator/internal/llm/openai"
+	"github.com/nianticlabs/venator/internal/result"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
 )
@@ -31,7 +32,7 @@ func New(llmConfig llmconfig.Config) (model.Client, error) {
 }
 
 // Process runs the LLM analysis on the query results.
-func Process(ctx context.Context, client model.Client, results []map[string]string, cfg *config.RuleConfig) ([]map[string]string, error) {
+func Process(ctx context.Context, client model.Client, results []result.Row, cfg *config.RuleConfig) ([]result.Row, error) {
 	if len(results) == 0 {
 		logger.Infof("No results to process with LLM")
 		return nil, nil
@@ -64,18 +65,19 @@ func Process(ctx context.Context, client model.Client, results []map[string]stri
 	return newResults, nil
 }
 
-func generatePrompt(promptTemplate string, results []map[string]string) (string, error) {
+func generatePrompt(promptTemplate string, results []result.Row) (string, error) {
 	// Format the results to be readable in the prompt
 	var formattedResults bytes.Buffer
-	for _, result := range results {
+	for _, row := range results {
 		var fields []string
+		flat := row.Flatten()
 
 		// Collect and sort keys alphabetically
-		keys := maps.Keys(result)
+		keys := maps.Keys(flat)
 		sort.Strings(keys)
 
 		for _, key := range keys {
-			value := result[key]
+			value := flat[key]
 			fields = append(fields, fmt.Sprintf("%s: %s", key, value))
 		}
 		line := strings.Join(fields, ", ")
@@ -99,7 +101,7 @@ func generatePrompt(promptTemplate string, results []map[string]string) (string,
 	return buf.String(), nil
 }
 
-func parseResponse(response string) ([]map[string]string, error) {
+func parseResponse(response string) ([]result.Row, error) {
 	// Remove any leading/trailing whitespace
 	response = strings.TrimSpace(response)
 
@@ -122,45 +124,18 @@ func parseResponse(response string) ([]map[string]string, error) {
 
 	logger.Debugf("Cleaned LLM response:\n%s", response)
 
-	// Try to unmarshal into a []map[string]interface{}
+	// Unmarshal into typed rows, keeping numbers, arrays and objects as they are
+	decoder := json.NewDecoder(strings.NewReader(response))
+	decoder.UseNumber()
 	var rawResults []map[string]interface{}
-	err := json.Unmarshal([]byte(response), &rawResults)
-	if err != nil {
+	if err := decoder.Decode(&rawResults); err != nil {
 		return nil, fmt.Errorf("error unmarshaling LLM response: %w", err)
 	}
 
-	// Convert []map[string]interface{} to []map[string]string
-	results := make([]map[string]string, len(rawResults))
+	results := make([]result.Row, len(rawResults))
 	for i, rawResult := range rawResults {
-		res, err := convertToMapStringString(rawResult)
-		if err != nil {
-			return nil, fmt.Errorf("error converting result at index %d: %w", i, err)
-		}
-		results[i] = res
+		results[i] = result.Row(result.Normalize(rawResult).(map[string]any))
 	}
 
 	return results, nil
 }
-
-func convertToMapStringString(m map[string]interface{}) (map[string]string, error) {
-	res := make(map[string]string)
-	for k, v := range m {
-		switch val := v.(type) {
-		case string:
-			res[k] = val
-		case nil:
-			res[k] = ""
-		case []interface{}, map[string]interface{}:
-			// Convert array or map to JSON string
-			jsonBytes, err := json.Marshal(val)
-			if err != nil {
-				return nil, fmt.Errorf("error marshaling %T to JSON string: %w", val, err)
-			}
-			res[k] = string(jsonBytes)
-		default:
-			// For other types, convert to string
-			res[k] = fmt.Sprintf("%v", val)
-		}
-	}
-	return res, nil
-}
@@ -6,7 +6,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 )
 
 type mockLLMClient struct {
@@ -56,7 +59,7 @@ signal_name: PrivilegeEscalation, timestamp: 2023-10-10 08:05:00, user: test-use
 	for _, tt := range tests {
 		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
-			prompt, err := generatePrompt(tt.prompt, tt.results)
+			prompt, err := generatePrompt(tt.prompt, result.FromStringMaps(tt.results))
 			if (err != nil) != tt.expectError {
 				t.Fatalf("generatePrompt() error = %v, expectError %v", err, tt.expectError)
 			}
@@ -164,10 +167,10 @@ func TestParseResponse(t *testing.T) {
 			}
 
 			if tt.expectedResult != nil {
-				for i, result := range results {
+				for i, row := range results {
 					for key, expectedValue := range tt.expectedResult[i] {
-						if result[key] != expectedValue {
-							t.Errorf("At index %d, key '%s': expected '%s', got '%s'", i, key, expectedValue, result[key])
+						if got, _ := row.GetString(key); got != expectedValue {
+							t.Errorf("At index %d, key '%s': expected '%s', got '%s'", i, key, expectedValue, got)
 						}
 					}
 				}
@@ -253,7 +256,7 @@ func TestProcess(t *testing.T) {
 				},
 			}
 
-			newResults, err := Process(ctx, llmClient, tt.results, cfg)
+			newResults, err := Process(ctx, llmClient, result.FromStringMaps(tt.results), cfg)
 			if (err != nil) != tt.expectError {
 				t.Fatalf("Process() error = %v, expectError %v", err, tt.expectError)
 			}
@@ -265,10 +268,10 @@ func TestProcess(t *testing.T) {
 				t.Fatalf("Expected %d result(s), got %d", len(tt.expectedResult), len(newResults))
 			}
 
-			for i, result := range newResults {
+			for i, row := range newResults {
 				for key, expectedValue := range tt.expectedResult[i] {
-					if result[key] != expectedValue {
-						t.Errorf("Key '%s': expected '%s', got '%s'", key, expectedValue, result[key])
+					if got, _ := row.GetString(key); got != expectedValue {
+						t.Errorf("Key '%s': expected '%s', got '%s'", key, expectedValue, got)
 					}
 				}
 			}
@@ -276,53 +279,26 @@ func TestProcess(t *testing.T) {
 	}
 }
 
-func TestConvertToMapStringString(t *testing.T) {
-	tests := []struct {
-		name           string
-		input          map[string]interface{}
-		expectedOutput map[string]string
-		expectError    bool
-	}{
+func TestParseResponseTypes(t *testing.T) {
+	response := `[{"string": "value", "number": 42, "float": 3.14, "bool": true, "nil": null, "array": ["item1", "item2"], "map": {"key": "value"}}]`
+
+	expected := []result.Row{
 		{
-			name: "Valid conversion with various types",
-			input: map[string]interface{}{
-				"string": "value",
-				"number": 42,
-				"float":  3.14,
-				"bool":   true,
-				"nil":    nil,
-				"array":  []interface{}{"item1", "item2"},
-				"map":    map[string]interface{}{"key": "value"},
-			},
-			expectedOutput: map[string]string{
-				"string": "value",
-				"number": "42",
-				"float":  "3.14",
-				"bool":   "true",
-				"nil":    "",
-				"array":  `["item1","item2"]`,
-				"map":    `{"key":"value"}`,
-			},
-			expectError: false,
+			"string": "value",
+			"number": int64(42),
+			"float":  3.14,
+			"bool":   true,
+			"nil":    nil,
+			"array":  []any{"item1", "item2"},
+			"map":    map[string]any{"key": "value"},
 		},
 	}
 
-	for _, tt := range tests {
-		tt := tt // capture range variable
-		t.Run(tt.name, func(t *testing.T) {
-			output, err := convertToMapStringString(tt.input)
-			if (err != nil) != tt.expectError {
-				t.Fatalf("convertToMapStringString() error = %v, expectError %v", err, tt.expectError)
-			}
-			if err != nil {
-				return
-			}
-
-			for key, expectedValue := range tt.expectedOutput {
-				if output[key] != expectedValue {
-					t.Errorf("Key '%s': expected '%s', got '%s'", key, expectedValue, output[key])
-				}
-			}
-		})
+	results, err := parseResponse(response)
+	if err != nil {
+		t.Fatalf("parseResponse() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
 	}
 }
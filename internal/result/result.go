@@ -0,0 +1,229 @@
+// Package result defines the rows passed from query runners through exclusions and the LLM to publishers.
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Row is a single query result. Values are JSON-compatible: nil, bool, int64, float64, string,
+// []any and map[string]any, so numbers, arrays and nested objects survive until they are published.
+// Nested values are addressed by dotted paths such as actor.user.name.
+type Row map[string]any
+
+// Get returns the value at a dotted path. A key matching the full path takes precedence over nested
+// objects, so rows whose columns are already named like actor.user.name resolve the same way.
+func (r Row) Get(path string) (any, bool) {
+	return lookup(r, path)
+}
+
+// GetString returns the value at a dotted path formatted by FormatValue.
+func (r Row) GetString(path string) (string, bool) {
+	v, ok := r.Get(path)
+	if !ok {
+		return "", false
+	}
+	return FormatValue(v), true
+}
+
+// Flatten returns the row as a map from dotted paths to formatted values, the form results had
+// before rows were typed. Nested objects become dotted keys and arrays are encoded as JSON.
+func (r Row) Flatten() map[string]string {
+	out := make(map[string]string)
+	flatten("", r, out)
+	return out
+}
+
+// Set sets the value at a dotted path so that Get finds it. An existing key or nested object along
+// the path is updated; otherwise the missing objects are created, unless a value that is not an
+// object is in the way, in which case the full path is set as a key.
+func (r Row) Set(path string, value any) {
+	if !set(r, path, value) {
+		r[path] = value
+	}
+}
+
+func set(m map[string]any, path string, value any) bool {
+	if _, ok := m[path]; ok {
+		m[path] = value
+		return true
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		if child, ok := asObject(m[path[:i]]); ok && set(child, path[i+1:], value) {
+			return true
+		}
+	}
+
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		m[path] = value
+		return true
+	}
+	if _, ok := m[head]; ok {
+		return false
+	}
+	child := make(map[string]any)
+	set(child, rest, value)
+	m[head] = child
+	return true
+}
+
+func lookup(m map[string]any, path string) (any, bool) {
+	if v, ok := m[path]; ok {
+		return v, true
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		child, ok := asObject(m[path[:i]])
+		if !ok {
+			continue
+		}
+		if v, ok := lookup(child, path[i+1:]); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func flatten(prefix string, m map[string]any, out map[string]string) {
+	for k, v := range m {
+		key := prefix + k
+		if child, ok := asObject(v); ok {
+			flatten(key+".", child, out)
+			continue
+		}
+		out[key] = FormatValue(v)
+	}
+}
+
+func asObject(v any) (map[string]any, bool) {
+	switch val := v.(type) {
+	case map[string]any:
+		return val, true
+	case Row:
+		return val, true
+	default:
+		return nil, false
+	}
+}
+
+// FormatValue formats a row value as a string: strings as is, nil as empty, and everything else
+// in its JSON form.
+func FormatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	default:
+		valJSON, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(valJSON)
+	}
+}
+
+// FromStrings converts a map of string values, e.g. produced by a legacy query runner, into a row.
+func FromStrings(m map[string]string) Row {
+	r := make(Row, len(m))
+	for k, v := range m {
+		r[k] = v
+	}
+	return r
+}
+
+// FromStringMaps converts multiple string maps into rows.
+func FromStringMaps(maps []map[string]string) []Row {
+	if maps == nil {
+		return nil
+	}
+	rows := make([]Row, len(maps))
+	for i, m := range maps {
+		rows[i] = FromStrings(m)
+	}
+	return rows
+}
+
+// Flatten converts rows into string maps for code that does not handle typed values.
+func Flatten(rows []Row) []map[string]string {
+	if rows == nil {
+		return nil
+	}
+	maps := make([]map[string]string, len(rows))
+	for i, r := range rows {
+		maps[i] = r.Flatten()
+	}
+	return maps
+}
+
+// FromJSON decodes a JSON object into a row. Integral numbers become int64 and others float64.
+func FromJSON(data []byte) (Row, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var obj map[string]any
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	return Row(Normalize(obj).(map[string]any)), nil
+}
+
+// Normalize converts a decoded JSON value into the value types used by rows. json.Number values
+// become int64 when they are integral and float64 otherwise; other numeric types become one of both.
+func Normalize(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return val.String()
+		}
+		return f
+	case int:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case float32:
+		return float64(val)
+	case map[string]any:
+		for k, child := range val {
+			val[k] = Normalize(child)
+		}
+		return val
+	case Row:
+		for k, child := range val {
+			val[k] = Normalize(child)
+		}
+		return map[string]any(val)
+	case []any:
+		for i, child := range val {
+			val[i] = Normalize(child)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,125 @@
+package result_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+func TestGet(t *testing.T) {
+	row := result.Row{
+		"actor":         map[string]any{"user": map[string]any{"name": "alice", "groups": []any{"admins"}}},
+		"src.ip":        "10.0.0.1",
+		"dst":           map[string]any{"port": int64(443)},
+		"dst.hostname":  "web-1",
+		"count":         int64(3),
+		"score":         0.5,
+		"admin":         true,
+		"comment":       nil,
+		"rule_specific": result.Row{"key": "value"},
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+		exists   bool
+	}{
+		{path: "actor.user.name", expected: "alice", exists: true},
+		{path: "actor.user.groups", expected: `["admins"]`, exists: true},
+		{path: "actor.user", expected: `{"groups":["admins"],"name":"alice"}`, exists: true},
+		{path: "src.ip", expected: "10.0.0.1", exists: true},
+		{path: "dst.port", expected: "443", exists: true},
+		{path: "dst.hostname", expected: "web-1", exists: true},
+		{path: "count", expected: "3", exists: true},
+		{path: "score", expected: "0.5", exists: true},
+		{path: "admin", expected: "true", exists: true},
+		{path: "comment", expected: "", exists: true},
+		{path: "rule_specific.key", expected: "value", exists: true},
+		{path: "actor.user.uid", exists: false},
+		{path: "src", exists: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, exists := row.GetString(tt.path)
+			if exists != tt.exists {
+				t.Fatalf("GetString(%q) exists = %v, want %v", tt.path, exists, tt.exists)
+			}
+			if value != tt.expected {
+				t.Errorf("GetString(%q) = %q, want %q", tt.path, value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	row := result.Row{
+		"actor":  map[string]any{"user": map[string]any{"name": "alice"}},
+		"count":  int64(3),
+		"ttps":   []any{"T1078", "T1110"},
+		"src.ip": "10.0.0.1",
+	}
+
+	expected := map[string]string{
+		"actor.user.name": "alice",
+		"count":           "3",
+		"ttps":            `["T1078","T1110"]`,
+		"src.ip":          "10.0.0.1",
+	}
+	if diff := cmp.Diff(expected, row.Flatten()); diff != "" {
+		t.Errorf("unexpected flattened row (-want +got):\n%s", diff)
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	row, err := result.FromJSON([]byte(`{"count": 3, "ratio": 0.25, "big": 12345678901234567890, "nested": {"ids": [1, 2]}}`))
+	if err != nil {
+		t.Fatalf("FromJSON() unexpected error: %v", err)
+	}
+
+	expected := result.Row{
+		"count":  int64(3),
+		"ratio":  0.25,
+		"big":    1.2345678901234567e+19,
+		"nested": map[string]any{"ids": []any{int64(1), int64(2)}},
+	}
+	if diff := cmp.Diff(expected, row); diff != "" {
+		t.Errorf("unexpected row (-want +got):\n%s", diff)
+	}
+
+	if _, err := result.FromJSON([]byte(`["not", "an", "object"]`)); err == nil {
+		t.Errorf("expected an error for a JSON array")
+	}
+	if _, err := result.FromJSON([]byte(`null`)); err == nil {
+		t.Errorf("expected an error for null")
+	}
+}
+
+func TestSet(t *testing.T) {
+	row := result.Row{
+		"actor":        map[string]any{"user": map[string]any{"name": "alice"}},
+		"src.ip":       "10.0.0.1",
+		"resource":     "web-1",
+		"device.owner": nil,
+	}
+
+	row.Set("actor.user.department", "security")
+	row.Set("src.ip", "10.0.0.2")
+	row.Set("device.owner", "bob")
+	row.Set("asset.criticality", "high")
+	row.Set("resource.owner", "carol")
+
+	expected := result.Row{
+		"actor":          map[string]any{"user": map[string]any{"name": "alice", "department": "security"}},
+		"src.ip":         "10.0.0.2",
+		"resource":       "web-1",
+		"resource.owner": "carol",
+		"device.owner":   "bob",
+		"asset":          map[string]any{"criticality": "high"},
+	}
+	if diff := cmp.Diff(expected, row); diff != "" {
+		t.Errorf("unexpected row (-want +got):\n%s", diff)
+	}
+}
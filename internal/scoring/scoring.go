@@ -0,0 +1,210 @@
+// Package scoring sets the confidence, severity and risk score of findings from conditions on
+// their result fields.
+package scoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/expr"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "internal/scoring")
+
+const (
+	// RiskField holds the sum of the risk of the scoring rules a finding matched.
+	RiskField = "venator.risk_score"
+	// EntityRiskField holds the sum of the risk scores of the findings of the run with the same entity.
+	EntityRiskField = "venator.entity_risk_score"
+)
+
+// Levels are ordered from lowest to highest, so rules can move a finding by a number of levels.
+var (
+	confidenceLevels = []string{
+		string(config.ConfidenceLow),
+		string(config.ConfidenceMedium),
+		string(config.ConfidenceHigh),
+	}
+	severityLevels = []string{
+		string(config.SeverityInformational),
+		string(config.SeverityLow),
+		string(config.SeverityMedium),
+		string(config.SeverityHigh),
+		string(config.SeverityCritical),
+	}
+)
+
+// Scorer applies the scoring rules of a rule to its findings.
+type Scorer struct {
+	rule  *config.RuleConfig
+	rules []scoringRule
+	// risk is set if any scoring rule adds risk, so findings get a risk score.
+	risk bool
+}
+
+type scoringRule struct {
+	when       *expr.Program
+	confidence adjustment
+	severity   adjustment
+	risk       int
+}
+
+// adjustment sets a level, or moves it by delta levels if level is empty.
+type adjustment struct {
+	level string
+	delta int
+}
+
+// New compiles the conditions of the scoring rules, reporting syntax and type errors.
+func New(rule *config.RuleConfig) (*Scorer, error) {
+	cfg := rule.Scoring
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("scoring requires at least one rule")
+	}
+	if cfg.MinEntityRisk < 0 {
+		return nil, fmt.Errorf("scoring minEntityRisk must not be negative")
+	}
+	vars := make(map[string]any, len(cfg.Lists))
+	for name, list := range cfg.Lists {
+		vars[name] = list
+	}
+
+	s := &Scorer{rule: rule, risk: cfg.Entity != "" || cfg.MinEntityRisk > 0}
+	for i, r := range cfg.Rules {
+		if r.When == "" {
+			return nil, fmt.Errorf("scoring rule %d requires a condition", i+1)
+		}
+		when, err := expr.CompileCondition(r.When, vars)
+		if err != nil {
+			return nil, fmt.Errorf("scoring rule %d: %w", i+1, err)
+		}
+		confidence, err := parseAdjustment(r.Confidence, confidenceLevels)
+		if err != nil {
+			return nil, fmt.Errorf("scoring rule %d: invalid confidence: %w", i+1, err)
+		}
+		severity, err := parseAdjustment(r.Severity, severityLevels)
+		if err != nil {
+			return nil, fmt.Errorf("scoring rule %d: invalid severity: %w", i+1, err)
+		}
+		s.rules = append(s.rules, scoringRule{when: when, confidence: confidence, severity: severity, risk: r.Risk})
+		if r.Risk != 0 {
+			s.risk = true
+		}
+	}
+	return s, nil
+}
+
+// parseAdjustment parses a level such as high or a number of levels such as +1 or -2.
+func parseAdjustment(s string, levels []string) (adjustment, error) {
+	if s == "" {
+		return adjustment{}, nil
+	}
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		delta, err := strconv.Atoi(s)
+		if err != nil {
+			return adjustment{}, fmt.Errorf("'%s' is not a number of levels", s)
+		}
+		return adjustment{delta: delta}, nil
+	}
+	for _, level := range levels {
+		if s == level {
+			return adjustment{level: s}, nil
+		}
+	}
+	return adjustment{}, fmt.Errorf("unsupported level '%s', expected one of %s or a number of levels such as +1",
+		s, strings.Join(levels, ", "))
+}
+
+// apply returns the level after the adjustment. Moving an unknown level up starts from the lowest
+// level, and levels are capped at both ends.
+func (a adjustment) apply(current string, levels []string) string {
+	if a.level != "" {
+		return a.level
+	}
+	if a.delta == 0 {
+		return current
+	}
+	i := -1
+	for j, level := range levels {
+		if level == current {
+			i = j
+		}
+	}
+	if i < 0 && a.delta < 0 {
+		return current
+	}
+	i = max(0, min(len(levels)-1, i+a.delta))
+	return levels[i]
+}
+
+// Score applies the scoring rules to every finding in order, sums the risk scores per entity
+// and drops the findings below the minimum entity risk. Findings whose condition fails to
+// evaluate are logged and left unchanged by that scoring rule.
+func (s *Scorer) Score(rows []result.Row) []result.Row {
+	cfg := s.rule.Scoring
+	risks := make([]int, len(rows))
+	entityRisks := make(map[string]int)
+	for i, row := range rows {
+		for _, r := range s.rules {
+			matched, err := r.when.EvalBool(row)
+			if err != nil {
+				logger.Warnf("rule %s: %s", s.rule.Name, err)
+				continue
+			}
+			if !matched {
+				continue
+			}
+			s.adjust(row, r)
+			risks[i] += r.risk
+		}
+		if s.risk {
+			row.Set(RiskField, int64(risks[i]))
+		}
+		if entity, ok := s.entity(row); ok {
+			entityRisks[entity] += risks[i]
+		}
+	}
+
+	var out []result.Row
+	for i, row := range rows {
+		risk := risks[i]
+		if entity, ok := s.entity(row); ok {
+			risk = entityRisks[entity]
+			row.Set(EntityRiskField, int64(risk))
+		}
+		if risk < cfg.MinEntityRisk {
+			continue
+		}
+		out = append(out, row)
+	}
+	if cfg.MinEntityRisk > 0 {
+		logger.Infof("rule %s: %d of %d result(s) reached the minimum entity risk", s.rule.Name, len(out), len(rows))
+	}
+	return out
+}
+
+func (s *Scorer) adjust(row result.Row, r scoringRule) {
+	confidence := string(signal.FindingConfidence(row, s.rule))
+	if level := r.confidence.apply(confidence, confidenceLevels); level != confidence {
+		row.Set(signal.ConfidenceField, level)
+	}
+	severity := string(signal.FindingSeverity(row, s.rule))
+	if level := r.severity.apply(severity, severityLevels); level != severity {
+		row.Set(signal.SeverityField, level)
+	}
+}
+
+// entity returns the entity of the finding, if the scoring block sets one and it is not empty.
+func (s *Scorer) entity(row result.Row) (string, bool) {
+	if s.rule.Scoring.Entity == "" {
+		return "", false
+	}
+	entity, ok := row.GetString(s.rule.Scoring.Entity)
+	return entity, ok && entity != ""
+}
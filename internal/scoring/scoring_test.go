@@ -0,0 +1,185 @@
+package scoring_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/scoring"
+)
+
+func TestScore(t *testing.T) {
+	rule := &config.RuleConfig{
+		Name:       "test-rule",
+		Confidence: config.ConfidenceLow,
+		Severity:   config.SeverityLow,
+		Scoring: &config.Scoring{
+			Lists: map[string][]string{"admins": {"alice"}},
+			Rules: []config.ScoringRule{
+				{When: "double(bytes_out) > 1e9", Confidence: "high", Severity: "+2", Risk: 50},
+				{When: "user in admins", Confidence: "+1", Severity: "+1", Risk: 20},
+				{When: "user == 'bob'", Severity: "-3"},
+			},
+			Entity: "user",
+		},
+	}
+	s, err := scoring.New(rule)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	rows := []result.Row{
+		{"user": "alice", "bytes_out": "2000000000"},
+		{"user": "alice", "bytes_out": int64(10)},
+		{"user": "bob", "bytes_out": 5e9},
+		{"bytes_out": int64(1)},
+	}
+	got := s.Score(rows)
+
+	expected := []result.Row{
+		{
+			"user": "alice", "bytes_out": "2000000000",
+			"venator": map[string]any{
+				"confidence": "high", "severity": "critical",
+				"risk_score": int64(70), "entity_risk_score": int64(90),
+			},
+		},
+		{
+			"user": "alice", "bytes_out": int64(10),
+			"venator": map[string]any{
+				"confidence": "medium", "severity": "medium",
+				"risk_score": int64(20), "entity_risk_score": int64(90),
+			},
+		},
+		{
+			"user": "bob", "bytes_out": 5e9,
+			"venator": map[string]any{
+				"confidence": "high", "severity": "informational",
+				"risk_score": int64(50), "entity_risk_score": int64(50),
+			},
+		},
+		{
+			"bytes_out": int64(1),
+			"venator":   map[string]any{"risk_score": int64(0)},
+		},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected rows (-want +got):\n%s", diff)
+	}
+}
+
+func TestScoreMinEntityRisk(t *testing.T) {
+	rule := &config.RuleConfig{
+		Scoring: &config.Scoring{
+			Rules:         []config.ScoringRule{{When: "failed", Risk: 10}},
+			Entity:        "user",
+			MinEntityRisk: 30,
+		},
+	}
+	s, err := scoring.New(rule)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	var rows []result.Row
+	for _, user := range []string{"alice", "alice", "alice", "bob", "bob"} {
+		rows = append(rows, result.Row{"user": user, "failed": true})
+	}
+	got := s.Score(rows)
+	if len(got) != 3 {
+		t.Fatalf("expected the findings of alice only, got %v", got)
+	}
+	for _, row := range got {
+		if row["user"] != "alice" {
+			t.Errorf("unexpected finding %v", row)
+		}
+	}
+}
+
+func TestScoreEvalError(t *testing.T) {
+	rule := &config.RuleConfig{
+		Confidence: config.ConfidenceLow,
+		Scoring: &config.Scoring{
+			Rules: []config.ScoringRule{{When: "bytes_out + 1 > 10", Confidence: "high"}},
+		},
+	}
+	s, err := scoring.New(rule)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	got := s.Score([]result.Row{{"bytes_out": "many"}})
+	if diff := cmp.Diff([]result.Row{{"bytes_out": "many"}}, got); diff != "" {
+		t.Errorf("expected the finding to be kept unchanged (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		scoring    config.Scoring
+		errMessage string
+	}{
+		{
+			name:       "no rules",
+			errMessage: "scoring requires at least one rule",
+		},
+		{
+			name:       "missing condition",
+			scoring:    config.Scoring{Rules: []config.ScoringRule{{Risk: 1}}},
+			errMessage: "scoring rule 1 requires a condition",
+		},
+		{
+			name:       "type error",
+			scoring:    config.Scoring{Rules: []config.ScoringRule{{When: "bytes_out > 'a' && user == 1 + true"}}},
+			errMessage: "scoring rule 1: invalid expression",
+		},
+		{
+			name:       "not a condition",
+			scoring:    config.Scoring{Rules: []config.ScoringRule{{When: "1 + 2"}}},
+			errMessage: "expected a bool condition, got int",
+		},
+		{
+			name:       "invalid confidence",
+			scoring:    config.Scoring{Rules: []config.ScoringRule{{When: "true", Confidence: "critical"}}},
+			errMessage: "scoring rule 1: invalid confidence: unsupported level 'critical'",
+		},
+		{
+			name:       "invalid severity delta",
+			scoring:    config.Scoring{Rules: []config.ScoringRule{{When: "true", Severity: "+x"}}},
+			errMessage: "scoring rule 1: invalid severity: '+x' is not a number of levels",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scoring.New(&config.RuleConfig{Scoring: &tt.scoring})
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+func TestExampleRules(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "config", "rules", "*", "*.yaml"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no example rules found: %v", err)
+	}
+	for _, path := range paths {
+		rule, err := config.ParseRuleConfig(path)
+		if err != nil {
+			t.Fatalf("ParseRuleConfig(%s) unexpected error: %v", path, err)
+		}
+		if rule.Scoring == nil {
+			continue
+		}
+		if _, err := scoring.New(rule); err != nil {
+			t.Errorf("%s: New() unexpected error: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package signal
+
+import (
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// ECSVersion is the Elastic Common Schema version ECS documents conform to.
+const ECSVersion = "8.11.0"
+
+// ECSDocument is a finding in Elastic Common Schema, for indexing into Elastic or OpenSearch
+// security views. Fields without an ECS equivalent are kept under the venator namespace.
+type ECSDocument struct {
+	Timestamp   string            `json:"@timestamp"`
+	Message     string            `json:"message,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ECS         ECSVersionInfo    `json:"ecs"`
+	Event       ECSEvent          `json:"event"`
+	Rule        ECSRule           `json:"rule"`
+	Threat      *ECSThreat        `json:"threat,omitempty"`
+	User        *ECSUser          `json:"user,omitempty"`
+	Source      *ECSEndpoint      `json:"source,omitempty"`
+	Destination *ECSEndpoint      `json:"destination,omitempty"`
+	Host        *ECSHost          `json:"host,omitempty"`
+	Process     *ECSProcess       `json:"process,omitempty"`
+	Venator     ECSVenator        `json:"venator"`
+}
+
+type ECSVersionInfo struct {
+	Version string `json:"version"`
+}
+
+type ECSEvent struct {
+	Kind     string   `json:"kind"`
+	Category []string `json:"category"`
+	Type     []string `json:"type"`
+	Module   string   `json:"module"`
+	Dataset  string   `json:"dataset"`
+	ID       string   `json:"id,omitempty"`
+	Created  string   `json:"created"`
+	// Severity is the OCSF severity ID of the finding.
+	Severity int `json:"severity,omitempty"`
+}
+
+type ECSRule struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Author      []string `json:"author,omitempty"`
+	Reference   string   `json:"reference,omitempty"`
+}
+
+// ECSThreat holds the MITRE ATT&CK mapping. ECS threat fields are arrays, one entry per TTP.
+type ECSThreat struct {
+	Framework string          `json:"framework"`
+	Tactic    ECSThreatEntity `json:"tactic"`
+	Technique ECSThreatEntity `json:"technique"`
+}
+
+type ECSThreatEntity struct {
+	ID        []string `json:"id"`
+	Name      []string `json:"name"`
+	Reference []string `json:"reference,omitempty"`
+}
+
+type ECSUser struct {
+	Name  string `json:"name,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type ECSHost struct {
+	Hostname string `json:"hostname,omitempty"`
+	IP       string `json:"ip,omitempty"`
+	OS       *ECSOS `json:"os,omitempty"`
+}
+
+type ECSOS struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type ECSProcess struct {
+	Name        string `json:"name,omitempty"`
+	PID         int    `json:"pid,omitempty"`
+	CommandLine string `json:"command_line,omitempty"`
+}
+
+type ECSEndpoint struct {
+	Address string `json:"address,omitempty"`
+	Domain  string `json:"domain,omitempty"`
+	IP      string `json:"ip,omitempty"`
+}
+
+type ECSVenator struct {
+	FindingUID   string         `json:"finding_uid"`
+	Confidence   string         `json:"confidence"`
+	ConfidenceID int            `json:"confidence_id"`
+	Severity     string         `json:"severity,omitempty"`
+	Resource     *Resource      `json:"resource,omitempty"`
+	EventIndex   string         `json:"event_index,omitempty"`
+	Extensions   map[string]any `json:"extensions,omitempty"`
+}
+
+// BuildECSDocument maps a result to an ECS document using the rule's field mapping.
+func BuildECSDocument(row result.Row, cfg *config.RuleConfig) (*ECSDocument, error) {
+	sig, err := BuildSignal(row, cfg)
+	if err != nil {
+		return nil, err
+	}
+	doc := &ECSDocument{
+		Timestamp: findingTime(sig).UTC().Format(time.RFC3339Nano),
+		Message:   sig.Message,
+		Tags:      cfg.Tags,
+		Labels:    sig.RuleSpecificData,
+		ECS:       ECSVersionInfo{Version: ECSVersion},
+		Event: ECSEvent{
+			Kind:     "alert",
+			Category: []string{"intrusion_detection"},
+			Type:     []string{"info"},
+			Module:   "venator",
+			Dataset:  "venator.findings",
+			ID:       sig.Metadata.EventID,
+			Created:  time.Now().UTC().Format(time.RFC3339Nano),
+			Severity: sig.SeverityID,
+		},
+		Rule: ECSRule{
+			ID:          cfg.UID,
+			Name:        cfg.Name,
+			Description: cfg.Description,
+		},
+		Threat:      buildECSThreat(cfg.TTPs),
+		Source:      buildECSEndpoint(sig.SrcEndpoint),
+		Destination: buildECSEndpoint(sig.DstEndpoint),
+		Venator: ECSVenator{
+			FindingUID:   sig.FindingUID,
+			Confidence:   sig.Confidence,
+			ConfidenceID: sig.ConfidenceID,
+			Severity:     sig.Severity,
+			EventIndex:   sig.Metadata.EventIndex,
+			Extensions:   sig.Extensions,
+		},
+	}
+	if cfg.Author != "" {
+		doc.Rule.Author = []string{cfg.Author}
+	}
+	if len(cfg.References) > 0 {
+		doc.Rule.Reference = cfg.References[0]
+	}
+	if sig.Actor.User != (User{}) {
+		doc.User = &ECSUser{Name: sig.Actor.User.Name, ID: sig.Actor.User.UID, Email: sig.Actor.User.Email}
+	}
+	if device := sig.Device; device != (Device{}) {
+		doc.Host = &ECSHost{Hostname: device.Hostname, IP: device.IP}
+		if device.OS != (OS{}) {
+			doc.Host.OS = &ECSOS{Name: device.OS.Name, Version: device.OS.Version}
+		}
+	}
+	if process := sig.Process; process != (Process{}) {
+		doc.Process = &ECSProcess{Name: process.Name, PID: process.PID, CommandLine: process.CmdLine}
+	}
+	if sig.Resource != (Resource{}) {
+		doc.Venator.Resource = &sig.Resource
+	}
+	return doc, nil
+}
+
+func buildECSThreat(ttps []config.TTP) *ECSThreat {
+	threat := &ECSThreat{Framework: "MITRE ATT&CK"}
+	for _, ttp := range ttps {
+		if ttp.ID == "" {
+			continue
+		}
+		if ttp.Tactic != "" {
+			threat.Tactic.ID = append(threat.Tactic.ID, mitreTactics[ttp.Tactic])
+			threat.Tactic.Name = append(threat.Tactic.Name, ttp.Tactic)
+		}
+		threat.Technique.ID = append(threat.Technique.ID, ttp.ID)
+		threat.Technique.Name = append(threat.Technique.Name, ttp.Name)
+		if ttp.Reference != "" {
+			threat.Technique.Reference = append(threat.Technique.Reference, ttp.Reference)
+		}
+	}
+	if len(threat.Technique.ID) == 0 {
+		return nil
+	}
+	return threat
+}
+
+// buildECSEndpoint maps an endpoint to ECS source or destination fields. address holds the IP if
+// known and the hostname otherwise.
+func buildECSEndpoint(endpoint Endpoint) *ECSEndpoint {
+	if endpoint == (Endpoint{}) {
+		return nil
+	}
+	address := endpoint.IP
+	if address == "" {
+		address = endpoint.Hostname
+	}
+	return &ECSEndpoint{Address: address, Domain: endpoint.Hostname, IP: endpoint.IP}
+}
@@ -0,0 +1,66 @@
+package signal_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func TestBuildECSDocument(t *testing.T) {
+	cfg := *ocsfRule
+	cfg.Output.Format = config.OutputFormatECS
+
+	output, err := signal.BuildOutput(ocsfRow, &cfg)
+	if err != nil {
+		t.Fatalf("BuildOutput() unexpected error: %v", err)
+	}
+	doc, ok := output.(*signal.ECSDocument)
+	if !ok {
+		t.Fatalf("expected an ECS document, got %T", output)
+	}
+
+	if doc.Timestamp != "2024-03-01T12:00:00Z" {
+		t.Errorf("unexpected @timestamp %q", doc.Timestamp)
+	}
+	if doc.Event.Kind != "alert" || doc.Rule.ID != "rule-uid" || doc.Rule.Name != "suspicious-login" {
+		t.Errorf("unexpected event/rule %+v %+v", doc.Event, doc.Rule)
+	}
+	expectedThreat := &signal.ECSThreat{
+		Framework: "MITRE ATT&CK",
+		Tactic:    signal.ECSThreatEntity{ID: []string{"TA0001"}, Name: []string{"Initial Access"}},
+		Technique: signal.ECSThreatEntity{
+			ID:        []string{"T1078"},
+			Name:      []string{"Valid Accounts"},
+			Reference: []string{"https://attack.mitre.org/techniques/T1078/"},
+		},
+	}
+	if diff := cmp.Diff(expectedThreat, doc.Threat); diff != "" {
+		t.Errorf("unexpected threat (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&signal.ECSUser{Name: "alice"}, doc.User); diff != "" {
+		t.Errorf("unexpected user (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&signal.ECSEndpoint{Address: "203.0.113.7", IP: "203.0.113.7"}, doc.Source); diff != "" {
+		t.Errorf("unexpected source (-want +got):\n%s", diff)
+	}
+	if doc.Destination != nil {
+		t.Errorf("expected no destination, got %+v", doc.Destination)
+	}
+	if diff := cmp.Diff(map[string]string{"country": "NZ"}, doc.Labels); diff != "" {
+		t.Errorf("unexpected labels (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildECSDocumentWithoutTTPs(t *testing.T) {
+	cfg := &config.RuleConfig{Name: "minimal", UID: "minimal-uid", Output: config.Output{Format: config.OutputFormatECS}}
+	doc, err := signal.BuildECSDocument(result.Row{"count": int64(1)}, cfg)
+	if err != nil {
+		t.Fatalf("BuildECSDocument() unexpected error: %v", err)
+	}
+	if doc.Threat != nil || doc.User != nil || doc.Source != nil {
+		t.Errorf("expected no threat, user or source, got %+v", doc)
+	}
+}
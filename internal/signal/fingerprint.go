@@ -0,0 +1,88 @@
+package signal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// FindingUIDField is the key of the finding UID set by SetFindingUIDs and added to raw outputs.
+const FindingUIDField = "finding_uid"
+
+// SetFindingUIDs stores the finding UID of every row in FindingUIDField. It is called on the rows
+// returned by the queries, before enrichment and scoring add fields, so the UID of a finding only
+// depends on the query results. A FindingUIDField returned by the query is replaced, and dropped
+// from rows whose UID cannot be computed.
+func SetFindingUIDs(rows []result.Row, cfg *config.RuleConfig) error {
+	var errs []error
+	for i, row := range rows {
+		delete(row, FindingUIDField)
+		uid, err := FindingUID(row, cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("result %d: %w", i+1, err))
+			continue
+		}
+		row[FindingUIDField] = uid
+	}
+	return errors.Join(errs...)
+}
+
+// FindingUID returns the fingerprint of the finding built from row: the UID stored by SetFindingUIDs,
+// or else one derived from the rule UID, the fingerprint key fields (or the whole result without
+// any) and the start of the fingerprint window, so publishers can use it as an idempotency key.
+func FindingUID(row result.Row, cfg *config.RuleConfig) (string, error) {
+	if uid, ok := row[FindingUIDField].(string); ok && uid != "" {
+		return uid, nil
+	}
+
+	h := sha256.New()
+	h.Write([]byte(cfg.UID))
+	h.Write([]byte{0})
+
+	fp := cfg.Fingerprint
+	if fp == nil || len(fp.Fields) == 0 {
+		rowJSON, err := json.Marshal(row)
+		if err != nil {
+			return "", err
+		}
+		h.Write(rowJSON)
+	} else {
+		for _, field := range fp.Fields {
+			value, _ := row.GetString(field)
+			h.Write([]byte(field))
+			h.Write([]byte{0})
+			h.Write([]byte(value))
+			h.Write([]byte{0})
+		}
+	}
+
+	if fp != nil && fp.Window > 0 {
+		start, err := findingTimestamp(row, cfg)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(start.UTC().Truncate(fp.Window).Format(time.RFC3339)))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// findingTimestamp returns the value mapped to the signal timestamp, or now if there is none.
+func findingTimestamp(row result.Row, cfg *config.RuleConfig) (time.Time, error) {
+	for _, f := range cfg.Output.Fields {
+		if path, _, err := resolvePath(f.Field); err != nil || path != "timestamp" {
+			continue
+		}
+		value, err := fieldValue(row, f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return parseSignalTime(value)
+	}
+	return time.Now(), nil
+}
@@ -0,0 +1,189 @@
+package signal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/enrichment"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/scoring"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func TestFindingUID(t *testing.T) {
+	keyed := &config.RuleConfig{
+		UID:         "rule-uid",
+		Fingerprint: &config.Fingerprint{Fields: []string{"user", "host"}, Window: time.Hour},
+		Output: config.Output{
+			Format: config.OutputFormatSignal,
+			Fields: []config.OutputField{{Field: "Timestamp", Source: "timestamp"}},
+		},
+	}
+	base := result.Row{"user": "alice", "host": "web-1", "timestamp": "2024-03-01T12:05:00Z", "count": int64(3)}
+
+	uid := func(row result.Row, cfg *config.RuleConfig) string {
+		t.Helper()
+		u, err := signal.FindingUID(row, cfg)
+		if err != nil {
+			t.Fatalf("FindingUID() unexpected error: %v", err)
+		}
+		return u
+	}
+	with := func(key string, value any) result.Row {
+		row := make(result.Row)
+		for k, v := range base {
+			row[k] = v
+		}
+		row[key] = value
+		return row
+	}
+
+	want := uid(base, keyed)
+	if len(want) != 32 {
+		t.Errorf("expected a 32 character uid, got %q", want)
+	}
+
+	tests := []struct {
+		name string
+		row  result.Row
+		cfg  *config.RuleConfig
+		same bool
+	}{
+		{name: "non-key field changes", row: with("count", int64(4)), cfg: keyed, same: true},
+		{name: "timestamp within the window", row: with("timestamp", "2024-03-01T12:55:00Z"), cfg: keyed, same: true},
+		{name: "key field changes", row: with("user", "bob"), cfg: keyed, same: false},
+		{name: "next window", row: with("timestamp", "2024-03-01T13:00:00Z"), cfg: keyed, same: false},
+		{name: "other rule", row: base, cfg: &config.RuleConfig{UID: "other", Fingerprint: keyed.Fingerprint, Output: keyed.Output}, same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uid(tt.row, tt.cfg); (got == want) != tt.same {
+				t.Errorf("expected same=%t, got %q and %q", tt.same, want, got)
+			}
+		})
+	}
+
+	// Without key fields the whole result identifies the finding.
+	unkeyed := &config.RuleConfig{UID: "rule-uid"}
+	if uid(base, unkeyed) == uid(with("count", int64(4)), unkeyed) {
+		t.Errorf("expected different uids for different results without key fields")
+	}
+}
+
+func TestFindingUIDStableAcrossEnrichment(t *testing.T) {
+	// Without key fields the whole result is hashed, so fields added after the query must not count.
+	rule := &config.RuleConfig{
+		UID:        "rule-uid",
+		Confidence: config.ConfidenceLow,
+		Enrichments: []config.Enrichment{{
+			QueryEngine: "bigquery",
+			Query:       "SELECT * FROM users",
+			Field:       "user",
+			Key:         "name",
+			Fields:      []config.EnrichmentField{{Field: "department", Source: "department"}},
+		}},
+		Scoring: &config.Scoring{
+			Rules:  []config.ScoringRule{{When: "department == 'finance'", Confidence: "high", Risk: 40}},
+			Entity: "user",
+		},
+	}
+	rows := []result.Row{{"user": "alice", "count": int64(3)}, {"user": "bob", "count": int64(1)}}
+
+	var want []string
+	for _, row := range rows {
+		uid, err := signal.FindingUID(row, rule)
+		if err != nil {
+			t.Fatalf("FindingUID() unexpected error: %v", err)
+		}
+		want = append(want, uid)
+	}
+	if err := signal.SetFindingUIDs(rows, rule); err != nil {
+		t.Fatalf("SetFindingUIDs() unexpected error: %v", err)
+	}
+
+	enricher, err := enrichment.New(context.Background(), rule, func(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error) {
+		return []result.Row{{"name": "alice", "department": "finance"}}, nil
+	})
+	if err != nil {
+		t.Fatalf("enrichment.New() unexpected error: %v", err)
+	}
+	enricher.Enrich(rows)
+	scorer, err := scoring.New(rule)
+	if err != nil {
+		t.Fatalf("scoring.New() unexpected error: %v", err)
+	}
+	rows = scorer.Score(rows)
+
+	if _, ok := rows[0].Get(scoring.RiskField); !ok {
+		t.Fatalf("expected the finding to be scored, got %v", rows[0])
+	}
+	for i, row := range rows {
+		got, err := signal.FindingUID(row, rule)
+		if err != nil {
+			t.Fatalf("FindingUID() unexpected error: %v", err)
+		}
+		if got != want[i] {
+			t.Errorf("finding %d: expected uid %q after enrichment and scoring, got %q", i+1, want[i], got)
+		}
+	}
+}
+
+func TestSetFindingUIDsReplacesQueryColumn(t *testing.T) {
+	rule := &config.RuleConfig{UID: "rule-uid"}
+	want, err := signal.FindingUID(result.Row{"user": "alice"}, rule)
+	if err != nil {
+		t.Fatalf("FindingUID() unexpected error: %v", err)
+	}
+
+	rows := []result.Row{{"user": "alice", signal.FindingUIDField: "forged"}}
+	if err := signal.SetFindingUIDs(rows, rule); err != nil {
+		t.Fatalf("SetFindingUIDs() unexpected error: %v", err)
+	}
+	if got := rows[0][signal.FindingUIDField]; got != want {
+		t.Errorf("expected the query column to be replaced by %q, got %v", want, got)
+	}
+}
+
+func TestFindingUIDInOutputs(t *testing.T) {
+	want, err := signal.FindingUID(ocsfRow, ocsfRule)
+	if err != nil {
+		t.Fatalf("FindingUID() unexpected error: %v", err)
+	}
+
+	for _, format := range []config.OutputFormat{
+		config.OutputFormatSignal, config.OutputFormatRaw, config.OutputFormatOCSF, config.OutputFormatECS, config.OutputFormatSTIX,
+	} {
+		t.Run(string(format), func(t *testing.T) {
+			cfg := *ocsfRule
+			cfg.Output.Format = format
+			output, err := signal.BuildOutput(ocsfRow, &cfg)
+			if err != nil {
+				t.Fatalf("BuildOutput() unexpected error: %v", err)
+			}
+
+			var got string
+			switch o := output.(type) {
+			case *signal.Signal:
+				got = o.FindingUID
+			case result.Row:
+				got, _ = o[signal.FindingUIDField].(string)
+			case *signal.DetectionFinding:
+				got = o.FindingInfo.UID
+			case *signal.ECSDocument:
+				got = o.Venator.FindingUID
+			case *signal.STIXBundle:
+				got = o.Objects[len(o.Objects)-1].CustomFindingUID
+			}
+			if got != want {
+				t.Errorf("expected finding uid %q, got %q", want, got)
+			}
+		})
+	}
+
+	if _, ok := ocsfRow[signal.FindingUIDField]; ok {
+		t.Errorf("raw output must not modify the result")
+	}
+}
@@ -0,0 +1,319 @@
+package signal
+
+import (
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// OCSFVersion is the OCSF schema version Detection Findings conform to.
+const OCSFVersion = "1.3.0"
+
+// Detection Finding class and activity identifiers.
+const (
+	ocsfCategoryUID    = 2
+	ocsfClassUID       = 2004
+	ocsfActivityCreate = 1
+	ocsfStatusNew      = 1
+	ocsfAnalyticRule   = 1
+)
+
+// Observable type identifiers.
+const (
+	ObservableHostname     = 1
+	ObservableIPAddress    = 2
+	ObservableUserName     = 4
+	ObservableEmailAddress = 5
+	ObservableProcessName  = 9
+	ObservableResourceUID  = 10
+	ObservableCommandLine  = 13
+)
+
+// DetectionFinding is an OCSF Detection Finding (class_uid 2004).
+type DetectionFinding struct {
+	ActivityID   int            `json:"activity_id"`
+	ActivityName string         `json:"activity_name"`
+	CategoryUID  int            `json:"category_uid"`
+	CategoryName string         `json:"category_name"`
+	ClassUID     int            `json:"class_uid"`
+	ClassName    string         `json:"class_name"`
+	TypeUID      int            `json:"type_uid"`
+	TypeName     string         `json:"type_name"`
+	Time         int64          `json:"time"`
+	SeverityID   int            `json:"severity_id"`
+	Severity     string         `json:"severity"`
+	ConfidenceID int            `json:"confidence_id"`
+	Confidence   string         `json:"confidence"`
+	StatusID     int            `json:"status_id"`
+	Status       string         `json:"status"`
+	Message      string         `json:"message,omitempty"`
+	FindingInfo  FindingInfo    `json:"finding_info"`
+	Metadata     OCSFMetadata   `json:"metadata"`
+	Observables  []Observable   `json:"observables,omitempty"`
+	Evidences    []Evidence     `json:"evidences"`
+	Resources    []Resource     `json:"resources,omitempty"`
+	Unmapped     map[string]any `json:"unmapped,omitempty"`
+}
+
+// FindingInfo describes the finding and the rule that produced it.
+type FindingInfo struct {
+	UID      string   `json:"uid"`
+	Title    string   `json:"title"`
+	Desc     string   `json:"desc,omitempty"`
+	Types    []string `json:"types,omitempty"`
+	Analytic Analytic `json:"analytic"`
+	Attacks  []Attack `json:"attacks,omitempty"`
+}
+
+// Analytic is the detection rule.
+type Analytic struct {
+	UID    string `json:"uid"`
+	Name   string `json:"name"`
+	TypeID int    `json:"type_id"`
+	Type   string `json:"type"`
+}
+
+// Attack is a MITRE ATT&CK tactic and technique.
+type Attack struct {
+	Tactic    *AttackTactic   `json:"tactic,omitempty"`
+	Technique AttackTechnique `json:"technique"`
+}
+
+type AttackTactic struct {
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name"`
+}
+
+type AttackTechnique struct {
+	UID    string `json:"uid"`
+	Name   string `json:"name,omitempty"`
+	SrcURL string `json:"src_url,omitempty"`
+}
+
+// OCSFMetadata identifies the product and schema version of a finding.
+type OCSFMetadata struct {
+	Version string      `json:"version"`
+	Product OCSFProduct `json:"product"`
+	UID     string      `json:"uid,omitempty"`
+}
+
+type OCSFProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+}
+
+// Observable is a value extracted from the finding for pivoting, e.g. an IP address.
+type Observable struct {
+	Name   string `json:"name"`
+	TypeID int    `json:"type_id"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+}
+
+// Evidence holds the artifacts the finding is based on. Data is the query result.
+type Evidence struct {
+	Actor       *OCSFActor   `json:"actor,omitempty"`
+	SrcEndpoint *Endpoint    `json:"src_endpoint,omitempty"`
+	DstEndpoint *Endpoint    `json:"dst_endpoint,omitempty"`
+	Device      *OCSFDevice  `json:"device,omitempty"`
+	Process     *OCSFProcess `json:"process,omitempty"`
+	Data        any          `json:"data,omitempty"`
+}
+
+type OCSFActor struct {
+	User OCSFUser `json:"user"`
+}
+
+type OCSFUser struct {
+	Name      string `json:"name,omitempty"`
+	UID       string `json:"uid,omitempty"`
+	EmailAddr string `json:"email_addr,omitempty"`
+}
+
+type OCSFDevice struct {
+	Hostname string  `json:"hostname,omitempty"`
+	IP       string  `json:"ip,omitempty"`
+	OS       *OCSFOS `json:"os,omitempty"`
+	TypeID   int     `json:"type_id"`
+}
+
+type OCSFOS struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	TypeID  int    `json:"type_id"`
+}
+
+type OCSFProcess struct {
+	Name    string `json:"name,omitempty"`
+	PID     int    `json:"pid,omitempty"`
+	CmdLine string `json:"cmd_line,omitempty"`
+}
+
+// mitreTactics maps ATT&CK Enterprise tactic names to their IDs.
+var mitreTactics = map[string]string{
+	"Reconnaissance":       "TA0043",
+	"Resource Development": "TA0042",
+	"Initial Access":       "TA0001",
+	"Execution":            "TA0002",
+	"Persistence":          "TA0003",
+	"Privilege Escalation": "TA0004",
+	"Defense Evasion":      "TA0005",
+	"Credential Access":    "TA0006",
+	"Discovery":            "TA0007",
+	"Lateral Movement":     "TA0008",
+	"Collection":           "TA0009",
+	"Command and Control":  "TA0011",
+	"Exfiltration":         "TA0010",
+	"Impact":               "TA0040",
+}
+
+var confidenceNames = map[int]string{
+	ConfidenceUnknown: "Unknown",
+	ConfidenceLow:     "Low",
+	ConfidenceMedium:  "Medium",
+	ConfidenceHigh:    "High",
+}
+
+var severityNames = map[int]string{
+	SeverityUnknown:       "Unknown",
+	SeverityInformational: "Informational",
+	SeverityLow:           "Low",
+	SeverityMedium:        "Medium",
+	SeverityHigh:          "High",
+	SeverityCritical:      "Critical",
+}
+
+// BuildDetectionFinding maps a result to an OCSF Detection Finding using the rule's field mapping.
+func BuildDetectionFinding(row result.Row, cfg *config.RuleConfig) (*DetectionFinding, error) {
+	sig, err := BuildSignal(row, cfg)
+	if err != nil {
+		return nil, err
+	}
+	finding := &DetectionFinding{
+		ActivityID:   ocsfActivityCreate,
+		ActivityName: "Create",
+		CategoryUID:  ocsfCategoryUID,
+		CategoryName: "Findings",
+		ClassUID:     ocsfClassUID,
+		ClassName:    "Detection Finding",
+		TypeUID:      ocsfClassUID*100 + ocsfActivityCreate,
+		TypeName:     "Detection Finding: Create",
+		Time:         findingTime(sig).UnixMilli(),
+		SeverityID:   sig.SeverityID,
+		Severity:     severityNames[sig.SeverityID],
+		ConfidenceID: sig.ConfidenceID,
+		Confidence:   confidenceNames[sig.ConfidenceID],
+		StatusID:     ocsfStatusNew,
+		Status:       "New",
+		Message:      sig.Message,
+		FindingInfo: FindingInfo{
+			UID:   sig.FindingUID,
+			Title: cfg.Name,
+			Desc:  cfg.Description,
+			Types: cfg.Tags,
+			Analytic: Analytic{
+				UID:    cfg.UID,
+				Name:   cfg.Name,
+				TypeID: ocsfAnalyticRule,
+				Type:   "Rule",
+			},
+			Attacks: buildAttacks(cfg.TTPs),
+		},
+		Metadata: OCSFMetadata{
+			Version: OCSFVersion,
+			Product: OCSFProduct{Name: "Venator", VendorName: "Niantic"},
+			UID:     sig.Metadata.EventID,
+		},
+		Observables: buildObservables(sig),
+		Unmapped:    unmapped(sig),
+	}
+	if sig.Resource != (Resource{}) {
+		finding.Resources = []Resource{sig.Resource}
+	}
+
+	evidence := Evidence{Data: row}
+	if user := sig.Actor.User; user != (User{}) {
+		evidence.Actor = &OCSFActor{User: OCSFUser{Name: user.Name, UID: user.UID, EmailAddr: user.Email}}
+	}
+	if sig.SrcEndpoint != (Endpoint{}) {
+		evidence.SrcEndpoint = &sig.SrcEndpoint
+	}
+	if sig.DstEndpoint != (Endpoint{}) {
+		evidence.DstEndpoint = &sig.DstEndpoint
+	}
+	if device := sig.Device; device != (Device{}) {
+		// Device and OS types aren't known, so both are Unknown.
+		evidence.Device = &OCSFDevice{Hostname: device.Hostname, IP: device.IP}
+		if device.OS != (OS{}) {
+			evidence.Device.OS = &OCSFOS{Name: device.OS.Name, Version: device.OS.Version}
+		}
+	}
+	if process := sig.Process; process != (Process{}) {
+		evidence.Process = &OCSFProcess{Name: process.Name, PID: process.PID, CmdLine: process.CmdLine}
+	}
+	finding.Evidences = []Evidence{evidence}
+
+	return finding, nil
+}
+
+// unmapped returns the rule-specific data and extensions, which have no OCSF attributes.
+func unmapped(sig *Signal) map[string]any {
+	if len(sig.RuleSpecificData) == 0 && len(sig.Extensions) == 0 {
+		return nil
+	}
+	out := make(map[string]any)
+	for k, v := range sig.RuleSpecificData {
+		out[k] = v
+	}
+	for k, v := range sig.Extensions {
+		out[k] = v
+	}
+	return out
+}
+
+// findingTime returns the time of the finding, or now if the rule doesn't map a timestamp.
+func findingTime(sig *Signal) time.Time {
+	if sig.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return sig.Timestamp
+}
+
+func buildAttacks(ttps []config.TTP) []Attack {
+	var attacks []Attack
+	for _, ttp := range ttps {
+		if ttp.ID == "" {
+			continue
+		}
+		attack := Attack{
+			Technique: AttackTechnique{UID: ttp.ID, Name: ttp.Name, SrcURL: ttp.Reference},
+		}
+		if ttp.Tactic != "" {
+			attack.Tactic = &AttackTactic{UID: mitreTactics[ttp.Tactic], Name: ttp.Tactic}
+		}
+		attacks = append(attacks, attack)
+	}
+	return attacks
+}
+
+func buildObservables(sig *Signal) []Observable {
+	var observables []Observable
+	add := func(name string, typeID int, typeName, value string) {
+		if value != "" {
+			observables = append(observables, Observable{Name: name, TypeID: typeID, Type: typeName, Value: value})
+		}
+	}
+	add("actor.user.name", ObservableUserName, "User Name", sig.Actor.User.Name)
+	add("actor.user.email_addr", ObservableEmailAddress, "Email Address", sig.Actor.User.Email)
+	add("resources[0].uid", ObservableResourceUID, "Resource UID", sig.Resource.UID)
+	add("src_endpoint.hostname", ObservableHostname, "Hostname", sig.SrcEndpoint.Hostname)
+	add("src_endpoint.ip", ObservableIPAddress, "IP Address", sig.SrcEndpoint.IP)
+	add("dst_endpoint.hostname", ObservableHostname, "Hostname", sig.DstEndpoint.Hostname)
+	add("dst_endpoint.ip", ObservableIPAddress, "IP Address", sig.DstEndpoint.IP)
+	add("device.hostname", ObservableHostname, "Hostname", sig.Device.Hostname)
+	add("device.ip", ObservableIPAddress, "IP Address", sig.Device.IP)
+	add("process.name", ObservableProcessName, "Process Name", sig.Process.Name)
+	add("process.cmd_line", ObservableCommandLine, "Command Line", sig.Process.CmdLine)
+	return observables
+}
@@ -0,0 +1,264 @@
+package signal_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var ocsfRule = &config.RuleConfig{
+	Name:        "suspicious-login",
+	UID:         "rule-uid",
+	Description: "Login from an unusual location",
+	Confidence:  config.ConfidenceHigh,
+	Severity:    config.SeverityMedium,
+	Tags:        []string{"identity"},
+	TTPs: []config.TTP{
+		{Framework: "MITRE", Tactic: "Initial Access", Name: "Valid Accounts", ID: "T1078", Reference: "https://attack.mitre.org/techniques/T1078/"},
+	},
+	Output: config.Output{
+		Format: config.OutputFormatOCSF,
+		Fields: []config.OutputField{
+			{Field: "Timestamp", Source: "timestamp"},
+			{Field: "ActorUserName", Source: "user"},
+			{Field: "SrcIP", Source: "ip"},
+			{Field: "ResourceUID", Source: "resource"},
+			{Field: "Message", Source: "message"},
+			{Field: "RuleSpecificData", Source: "details"},
+		},
+	},
+}
+
+var ocsfRow = result.Row{
+	"timestamp": "2024-03-01T12:00:00Z",
+	"user":      "alice",
+	"ip":        "203.0.113.7",
+	"resource":  "projects/example",
+	"message":   "alice logged in from 203.0.113.7",
+	"details":   `{"country": "NZ"}`,
+	"attempts":  int64(3),
+}
+
+func TestBuildDetectionFinding(t *testing.T) {
+	finding, err := signal.BuildDetectionFinding(ocsfRow, ocsfRule)
+	if err != nil {
+		t.Fatalf("BuildDetectionFinding() unexpected error: %v", err)
+	}
+
+	if finding.ClassUID != 2004 || finding.TypeUID != 200401 {
+		t.Errorf("unexpected class_uid %d / type_uid %d", finding.ClassUID, finding.TypeUID)
+	}
+	if finding.Time != 1709294400000 {
+		t.Errorf("unexpected time %d", finding.Time)
+	}
+	if finding.ConfidenceID != signal.ConfidenceHigh || finding.Confidence != "High" {
+		t.Errorf("unexpected confidence %d %q", finding.ConfidenceID, finding.Confidence)
+	}
+	if finding.SeverityID != signal.SeverityMedium || finding.Severity != "Medium" {
+		t.Errorf("unexpected severity %d %q", finding.SeverityID, finding.Severity)
+	}
+	if finding.FindingInfo.UID == "" {
+		t.Errorf("expected a finding uid")
+	}
+
+	expectedAttacks := []signal.Attack{{
+		Tactic:    &signal.AttackTactic{UID: "TA0001", Name: "Initial Access"},
+		Technique: signal.AttackTechnique{UID: "T1078", Name: "Valid Accounts", SrcURL: "https://attack.mitre.org/techniques/T1078/"},
+	}}
+	if diff := cmp.Diff(expectedAttacks, finding.FindingInfo.Attacks); diff != "" {
+		t.Errorf("unexpected attacks (-want +got):\n%s", diff)
+	}
+
+	expectedObservables := []signal.Observable{
+		{Name: "actor.user.name", TypeID: signal.ObservableUserName, Type: "User Name", Value: "alice"},
+		{Name: "resources[0].uid", TypeID: signal.ObservableResourceUID, Type: "Resource UID", Value: "projects/example"},
+		{Name: "src_endpoint.ip", TypeID: signal.ObservableIPAddress, Type: "IP Address", Value: "203.0.113.7"},
+	}
+	if diff := cmp.Diff(expectedObservables, finding.Observables); diff != "" {
+		t.Errorf("unexpected observables (-want +got):\n%s", diff)
+	}
+
+	if len(finding.Evidences) != 1 || finding.Evidences[0].DstEndpoint != nil {
+		t.Fatalf("unexpected evidences %+v", finding.Evidences)
+	}
+	if diff := cmp.Diff(ocsfRow, finding.Evidences[0].Data); diff != "" {
+		t.Errorf("unexpected evidence data (-want +got):\n%s", diff)
+	}
+
+	// The same result yields the same finding uid, so downstream consumers can deduplicate.
+	again, err := signal.BuildDetectionFinding(ocsfRow, ocsfRule)
+	if err != nil {
+		t.Fatalf("BuildDetectionFinding() unexpected error: %v", err)
+	}
+	if again.FindingInfo.UID != finding.FindingInfo.UID {
+		t.Errorf("expected stable finding uids, got %q and %q", finding.FindingInfo.UID, again.FindingInfo.UID)
+	}
+}
+
+func TestDetectionFindingSchema(t *testing.T) {
+	schema := loadSchema(t, "testdata/ocsf/detection_finding.schema.json")
+
+	minimalRule := &config.RuleConfig{
+		Name:   "minimal",
+		UID:    "minimal-uid",
+		Output: config.Output{Format: config.OutputFormatOCSF},
+	}
+
+	tests := []struct {
+		name string
+		row  result.Row
+		cfg  *config.RuleConfig
+	}{
+		{name: "fully mapped", row: ocsfRow, cfg: ocsfRule},
+		{name: "no field mapping", row: result.Row{"count": int64(12)}, cfg: minimalRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := signal.BuildOutput(tt.row, tt.cfg)
+			if err != nil {
+				t.Fatalf("BuildOutput() unexpected error: %v", err)
+			}
+			b, err := json.Marshal(output)
+			if err != nil {
+				t.Fatalf("failed to marshal finding: %v", err)
+			}
+			var doc any
+			if err := json.Unmarshal(b, &doc); err != nil {
+				t.Fatalf("failed to decode finding: %v", err)
+			}
+			if errs := schema.validate(schema.root, doc, "$"); len(errs) > 0 {
+				t.Errorf("finding does not match the OCSF schema:\n%s\n%s", strings.Join(errs, "\n"), b)
+			}
+		})
+	}
+}
+
+// jsonSchema validates documents against the subset of JSON schema used by the vendored OCSF schema:
+// type, const, enum, required, properties, additionalProperties, items, minItems, minProperties and
+// local $ref.
+type jsonSchema struct {
+	root map[string]any
+	defs map[string]any
+}
+
+func loadSchema(t *testing.T, path string) *jsonSchema {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(b, &root); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	defs, _ := root["$defs"].(map[string]any)
+	return &jsonSchema{root: root, defs: defs}
+}
+
+func (s *jsonSchema) validate(node map[string]any, value any, path string) []string {
+	if ref, ok := node["$ref"].(string); ok {
+		def, ok := s.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved $ref %s", path, ref)}
+		}
+		return s.validate(def, value, path)
+	}
+
+	var errs []string
+	if typ, ok := node["type"].(string); ok && !hasType(value, typ) {
+		return []string{fmt.Sprintf("%s: expected %s, got %T", path, typ, value)}
+	}
+	if c, ok := node["const"]; ok && !reflect.DeepEqual(c, value) {
+		errs = append(errs, fmt.Sprintf("%s: expected %v, got %v", path, c, value))
+	}
+	if enum, ok := node["enum"].([]any); ok && !containsValue(enum, value) {
+		errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", path, value, enum))
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		for _, r := range asSlice(node["required"]) {
+			if _, ok := val[r.(string)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing required attribute %s", path, r))
+			}
+		}
+		if min, ok := node["minProperties"].(float64); ok && len(val) < int(min) {
+			errs = append(errs, fmt.Sprintf("%s: expected at least %v attributes", path, min))
+		}
+		properties, _ := node["properties"].(map[string]any)
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop, ok := properties[k].(map[string]any)
+			if !ok {
+				if node["additionalProperties"] == false {
+					errs = append(errs, fmt.Sprintf("%s: unknown attribute %s", path, k))
+				}
+				continue
+			}
+			errs = append(errs, s.validate(prop, val[k], path+"."+k)...)
+		}
+	case []any:
+		if min, ok := node["minItems"].(float64); ok && len(val) < int(min) {
+			errs = append(errs, fmt.Sprintf("%s: expected at least %v items", path, min))
+		}
+		if items, ok := node["items"].(map[string]any); ok {
+			for i, item := range val {
+				errs = append(errs, s.validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+func hasType(value any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == float64(int64(f))
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return true
+	}
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
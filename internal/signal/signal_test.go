@@ -6,10 +6,15 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
 	"github.com/nianticlabs/venator/internal/signal"
 )
 
+// ignoreFindingUID ignores the computed finding UID, which is covered by the fingerprint tests.
+var ignoreFindingUID = cmpopts.IgnoreFields(signal.Signal{}, "FindingUID")
+
 func TestBuildSignal(t *testing.T) {
 	cfg := &config.RuleConfig{
 		Name: "test-rule",
@@ -169,7 +174,7 @@ func TestBuildSignal(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := &config.RuleConfig{}
 			*cfg = *tt.cfg
-			signal, err := signal.BuildSignal(tt.result, cfg)
+			signal, err := signal.BuildSignal(result.FromStrings(tt.result), cfg)
 			if err != nil {
 				if tt.expected != nil {
 					t.Fatalf("unexpected error: %v", err)
@@ -179,9 +184,126 @@ func TestBuildSignal(t *testing.T) {
 				}
 				return
 			}
-			if diff := cmp.Diff(tt.expected, signal); diff != "" {
+			if diff := cmp.Diff(tt.expected, signal, ignoreFindingUID); diff != "" {
 				t.Fatalf("unexpected result (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestBuildSignalTypedRow(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Name: "test-rule",
+		UID:  "test-uid",
+		Output: config.Output{
+			Fields: []config.OutputField{
+				{Field: "Timestamp", Source: "timestamp"},
+				{Field: "ActorUserName", Source: "actor.user.name"},
+				{Field: "SrcIP", Source: "src.ip"},
+				{Field: "RuleSpecificData", Source: "details"},
+			},
+		},
+	}
+	row := result.Row{
+		"timestamp": "2023-05-14T10:00:00Z",
+		"actor":     map[string]any{"user": map[string]any{"name": "user123"}},
+		"src.ip":    "10.0.0.1",
+		"details":   map[string]any{"attempts": int64(3), "countries": []any{"US", "FR"}},
+	}
+
+	expected := &signal.Signal{
+		Timestamp:   time.Date(2023, 5, 14, 10, 0, 0, 0, time.UTC),
+		Rule_ID:     cfg.UID,
+		Rule_Name:   cfg.Name,
+		TTPs:        []map[string]string{},
+		Actor:       signal.Actor{User: signal.User{Name: "user123"}},
+		SrcEndpoint: signal.Endpoint{IP: "10.0.0.1"},
+		RuleSpecificData: map[string]string{
+			"attempts":  "3",
+			"countries": `["US","FR"]`,
+		},
+	}
+
+	sig, err := signal.BuildSignal(row, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, sig, ignoreFindingUID); diff != "" {
+		t.Fatalf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestFindingConfidence(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Name:       "test-rule",
+		UID:        "test-uid",
+		Confidence: config.ConfidenceMedium,
+		Output:     config.Output{Fields: []config.OutputField{{Field: "message", Source: "message"}}},
+	}
+
+	row := result.Row{"message": "hello"}
+	signal.RaiseConfidence(row, cfg, config.ConfidenceLow)
+	if _, ok := row.Get(signal.ConfidenceField); ok {
+		t.Errorf("expected a lower confidence to be ignored, got %v", row)
+	}
+
+	signal.RaiseConfidence(row, cfg, config.ConfidenceHigh)
+	signal.RaiseConfidence(row, cfg, config.ConfidenceMedium)
+	if got := signal.FindingConfidence(row, cfg); got != config.ConfidenceHigh {
+		t.Errorf("FindingConfidence() = %s, want %s", got, config.ConfidenceHigh)
+	}
+
+	sig, err := signal.BuildSignal(row, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sig.Confidence != string(config.ConfidenceHigh) || sig.ConfidenceID != signal.ConfidenceHigh {
+		t.Errorf("unexpected signal confidence %s (%d)", sig.Confidence, sig.ConfidenceID)
+	}
+}
+
+func TestFindingSeverity(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Name:     "test-rule",
+		UID:      "test-uid",
+		Severity: config.SeverityLow,
+		Output:   config.Output{Fields: []config.OutputField{{Field: "message", Source: "message"}}},
+	}
+
+	row := result.Row{"message": "hello"}
+	if got := signal.FindingSeverity(row, cfg); got != config.SeverityLow {
+		t.Errorf("FindingSeverity() = %s, want %s", got, config.SeverityLow)
+	}
+
+	row.Set(signal.SeverityField, string(config.SeverityCritical))
+	sig, err := signal.BuildSignal(row, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sig.Severity != string(config.SeverityCritical) || sig.SeverityID != signal.SeverityCritical {
+		t.Errorf("unexpected signal severity %s (%d)", sig.Severity, sig.SeverityID)
+	}
+}
+
+func TestHighestLevels(t *testing.T) {
+	cfg := &config.RuleConfig{Confidence: config.ConfidenceMedium, Severity: config.SeverityMedium}
+	rows := []result.Row{
+		{"venator.confidence": "low", "venator.severity": "low"},
+		{"venator.severity": "critical"},
+		{"venator.confidence": "high"},
+	}
+
+	if got := signal.HighestConfidence(rows, cfg); got != config.ConfidenceHigh {
+		t.Errorf("HighestConfidence() = %s, want %s", got, config.ConfidenceHigh)
+	}
+	if got := signal.HighestSeverity(rows, cfg); got != config.SeverityCritical {
+		t.Errorf("HighestSeverity() = %s, want %s", got, config.SeverityCritical)
+	}
+	// A finding confidence lowered by scoring is not raised back to the rule confidence.
+	if got := signal.HighestConfidence(rows[:1], cfg); got != config.ConfidenceLow {
+		t.Errorf("HighestConfidence() = %s, want %s", got, config.ConfidenceLow)
+	}
+	if got := signal.HighestSeverity(nil, cfg); got != config.SeverityMedium {
+		t.Errorf("HighestSeverity() = %s, want %s", got, config.SeverityMedium)
+	}
+}
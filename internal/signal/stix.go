@@ -0,0 +1,284 @@
+package signal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// stixTimeFormat is the STIX timestamp format, in UTC with millisecond precision.
+const stixTimeFormat = "2006-01-02T15:04:05.000Z"
+
+var (
+	// stixNamespace derives deterministic IDs for the objects Venator creates, so the same rule
+	// and finding always map to the same STIX objects.
+	stixNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/nianticlabs/venator"))
+	// stixSCONamespace is the namespace the STIX specification defines for cyber observable IDs.
+	stixSCONamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+)
+
+// STIXBundle is a STIX 2.1 bundle holding a finding: the rule as an Indicator, the ATT&CK
+// techniques as Attack-Patterns, the observed entities and a Sighting of the Indicator.
+type STIXBundle struct {
+	Type    string       `json:"type"`
+	ID      string       `json:"id"`
+	Objects []STIXObject `json:"objects"`
+}
+
+// STIXObject is a STIX domain, relationship or cyber observable object. Only the properties of
+// the object's type are set.
+type STIXObject struct {
+	Type        string `json:"type"`
+	SpecVersion string `json:"spec_version"`
+	ID          string `json:"id"`
+	Created     string `json:"created,omitempty"`
+	Modified    string `json:"modified,omitempty"`
+	CreatedBy   string `json:"created_by_ref,omitempty"`
+
+	Name                string                  `json:"name,omitempty"`
+	Description         string                  `json:"description,omitempty"`
+	IdentityClass       string                  `json:"identity_class,omitempty"`
+	Labels              []string                `json:"labels,omitempty"`
+	Confidence          *int                    `json:"confidence,omitempty"`
+	ExternalReferences  []STIXExternalReference `json:"external_references,omitempty"`
+	KillChainPhases     []STIXKillChainPhase    `json:"kill_chain_phases,omitempty"`
+	IndicatorTypes      []string                `json:"indicator_types,omitempty"`
+	Pattern             string                  `json:"pattern,omitempty"`
+	PatternType         string                  `json:"pattern_type,omitempty"`
+	ValidFrom           string                  `json:"valid_from,omitempty"`
+	RelationshipType    string                  `json:"relationship_type,omitempty"`
+	SourceRef           string                  `json:"source_ref,omitempty"`
+	TargetRef           string                  `json:"target_ref,omitempty"`
+	FirstSeen           string                  `json:"first_seen,omitempty"`
+	LastSeen            string                  `json:"last_seen,omitempty"`
+	FirstObserved       string                  `json:"first_observed,omitempty"`
+	LastObserved        string                  `json:"last_observed,omitempty"`
+	NumberObserved      int                     `json:"number_observed,omitempty"`
+	Count               int                     `json:"count,omitempty"`
+	SightingOfRef       string                  `json:"sighting_of_ref,omitempty"`
+	ObservedDataRefs    []string                `json:"observed_data_refs,omitempty"`
+	ObjectRefs          []string                `json:"object_refs,omitempty"`
+	Value               string                  `json:"value,omitempty"`
+	UserID              string                  `json:"user_id,omitempty"`
+	AccountLogin        string                  `json:"account_login,omitempty"`
+	CustomFindingUID    string                  `json:"x_venator_finding_uid,omitempty"`
+	CustomRuleSpecifics map[string]string       `json:"x_venator_rule_specific_data,omitempty"`
+}
+
+type STIXExternalReference struct {
+	SourceName string `json:"source_name"`
+	ExternalID string `json:"external_id,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+type STIXKillChainPhase struct {
+	KillChainName string `json:"kill_chain_name"`
+	PhaseName     string `json:"phase_name"`
+}
+
+// stixConfidence maps confidence levels to the STIX 0-100 scale.
+var stixConfidence = map[int]int{
+	ConfidenceLow:    15,
+	ConfidenceMedium: 50,
+	ConfidenceHigh:   85,
+}
+
+// BuildSTIXBundle maps a result to a STIX 2.1 bundle using the rule's field mapping.
+func BuildSTIXBundle(row result.Row, cfg *config.RuleConfig) (*STIXBundle, error) {
+	sig, err := BuildSignal(row, cfg)
+	if err != nil {
+		return nil, err
+	}
+	uid := sig.FindingUID
+	ts := findingTime(sig).UTC().Format(stixTimeFormat)
+
+	identity := STIXObject{
+		Type:          "identity",
+		SpecVersion:   "2.1",
+		ID:            stixID("identity", "venator"),
+		Created:       ts,
+		Modified:      ts,
+		Name:          "Venator",
+		IdentityClass: "system",
+	}
+
+	patternType := cfg.Language
+	if patternType == "" {
+		patternType = "venator"
+	}
+	indicator := STIXObject{
+		Type:           "indicator",
+		SpecVersion:    "2.1",
+		ID:             stixID("indicator", cfg.UID),
+		Created:        ts,
+		Modified:       ts,
+		CreatedBy:      identity.ID,
+		Name:           cfg.Name,
+		Description:    cfg.Description,
+		Labels:         cfg.Tags,
+		IndicatorTypes: []string{"malicious-activity"},
+		Pattern:        cfg.Query,
+		PatternType:    patternType,
+		ValidFrom:      ts,
+	}
+	if confidence, ok := stixConfidence[sig.ConfidenceID]; ok {
+		indicator.Confidence = &confidence
+	}
+	for _, ref := range cfg.References {
+		indicator.ExternalReferences = append(indicator.ExternalReferences, STIXExternalReference{SourceName: "reference", URL: ref})
+	}
+
+	objects := []STIXObject{identity, indicator}
+	for _, ttp := range cfg.TTPs {
+		if ttp.ID == "" {
+			continue
+		}
+		pattern := STIXObject{
+			Type:        "attack-pattern",
+			SpecVersion: "2.1",
+			ID:          stixID("attack-pattern", ttp.ID),
+			Created:     ts,
+			Modified:    ts,
+			CreatedBy:   identity.ID,
+			Name:        ttp.Name,
+			ExternalReferences: []STIXExternalReference{
+				{SourceName: "mitre-attack", ExternalID: ttp.ID, URL: ttp.Reference},
+			},
+		}
+		if pattern.Name == "" {
+			pattern.Name = ttp.ID
+		}
+		if ttp.Tactic != "" {
+			pattern.KillChainPhases = []STIXKillChainPhase{{KillChainName: "mitre-attack", PhaseName: phaseName(ttp.Tactic)}}
+		}
+		objects = append(objects, pattern, STIXObject{
+			Type:             "relationship",
+			SpecVersion:      "2.1",
+			ID:               stixID("relationship", indicator.ID+"|indicates|"+pattern.ID),
+			Created:          ts,
+			Modified:         ts,
+			CreatedBy:        identity.ID,
+			RelationshipType: "indicates",
+			SourceRef:        indicator.ID,
+			TargetRef:        pattern.ID,
+		})
+	}
+
+	sighting := STIXObject{
+		Type:                "sighting",
+		SpecVersion:         "2.1",
+		ID:                  stixID("sighting", cfg.UID+"|"+uid),
+		Created:             ts,
+		Modified:            ts,
+		CreatedBy:           identity.ID,
+		Description:         sig.Message,
+		FirstSeen:           ts,
+		LastSeen:            ts,
+		Count:               1,
+		SightingOfRef:       indicator.ID,
+		CustomFindingUID:    uid,
+		CustomRuleSpecifics: sig.RuleSpecificData,
+	}
+
+	observables := stixObservables(sig)
+	if len(observables) > 0 {
+		observed := STIXObject{
+			Type:           "observed-data",
+			SpecVersion:    "2.1",
+			ID:             stixID("observed-data", cfg.UID+"|"+uid),
+			Created:        ts,
+			Modified:       ts,
+			CreatedBy:      identity.ID,
+			FirstObserved:  ts,
+			LastObserved:   ts,
+			NumberObserved: 1,
+		}
+		for _, o := range observables {
+			observed.ObjectRefs = append(observed.ObjectRefs, o.ID)
+		}
+		objects = append(objects, observables...)
+		objects = append(objects, observed)
+		sighting.ObservedDataRefs = []string{observed.ID}
+	}
+	objects = append(objects, sighting)
+
+	return &STIXBundle{
+		Type:    "bundle",
+		ID:      stixID("bundle", cfg.UID+"|"+uid),
+		Objects: objects,
+	}, nil
+}
+
+// stixObservables returns cyber observables for the mapped user and endpoint IPs, deduplicated by ID.
+func stixObservables(sig *Signal) []STIXObject {
+	var observables []STIXObject
+	seen := make(map[string]bool)
+	add := func(o STIXObject) {
+		if !seen[o.ID] {
+			seen[o.ID] = true
+			observables = append(observables, o)
+		}
+	}
+
+	if user := sig.Actor.User; user != (User{}) {
+		id := user.UID
+		if id == "" {
+			id = user.Name
+		}
+		add(STIXObject{
+			Type:         "user-account",
+			SpecVersion:  "2.1",
+			ID:           scoID("user-account", `{"user_id":`+quote(id)+`}`),
+			UserID:       id,
+			AccountLogin: user.Name,
+		})
+	}
+	for _, ip := range []string{sig.SrcEndpoint.IP, sig.DstEndpoint.IP} {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			continue
+		}
+		typ := "ipv6-addr"
+		if parsed.To4() != nil {
+			typ = "ipv4-addr"
+		}
+		add(STIXObject{
+			Type:        typ,
+			SpecVersion: "2.1",
+			ID:          scoID(typ, `{"value":`+quote(ip)+`}`),
+			Value:       ip,
+		})
+	}
+	return observables
+}
+
+// stixID returns a deterministic ID for a Venator object.
+func stixID(typ, name string) string {
+	return typ + "--" + uuid.NewSHA1(stixNamespace, []byte(typ+"|"+name)).String()
+}
+
+// scoID returns the ID of a cyber observable, a UUIDv5 of its ID contributing properties as
+// canonical JSON.
+func scoID(typ, properties string) string {
+	return typ + "--" + uuid.NewSHA1(stixSCONamespace, []byte(properties)).String()
+}
+
+// phaseName converts an ATT&CK tactic name to its kill chain phase name, e.g. initial-access.
+func phaseName(tactic string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tactic)), " ", "-")
+}
+
+// quote encodes s as a JSON string without HTML escaping, as canonical JSON requires.
+func quote(s string) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,90 @@
+package signal_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+var stixIDPattern = regexp.MustCompile(`^[a-z0-9-]+--[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+func TestBuildSTIXBundle(t *testing.T) {
+	cfg := *ocsfRule
+	cfg.Output.Format = config.OutputFormatSTIX
+	cfg.Language = "sql"
+	cfg.Query = "SELECT * FROM logins"
+
+	output, err := signal.BuildOutput(ocsfRow, &cfg)
+	if err != nil {
+		t.Fatalf("BuildOutput() unexpected error: %v", err)
+	}
+	bundle, ok := output.(*signal.STIXBundle)
+	if !ok {
+		t.Fatalf("expected a STIX bundle, got %T", output)
+	}
+	if bundle.Type != "bundle" || !stixIDPattern.MatchString(bundle.ID) {
+		t.Errorf("unexpected bundle %s %s", bundle.Type, bundle.ID)
+	}
+
+	byType := make(map[string][]signal.STIXObject)
+	ids := make(map[string]bool)
+	for _, o := range bundle.Objects {
+		if o.SpecVersion != "2.1" || !stixIDPattern.MatchString(o.ID) {
+			t.Errorf("unexpected object %s with spec_version %q", o.ID, o.SpecVersion)
+		}
+		byType[o.Type] = append(byType[o.Type], o)
+		ids[o.ID] = true
+	}
+	for typ, count := range map[string]int{
+		"identity": 1, "indicator": 1, "attack-pattern": 1, "relationship": 1,
+		"user-account": 1, "ipv4-addr": 1, "observed-data": 1, "sighting": 1,
+	} {
+		if len(byType[typ]) != count {
+			t.Errorf("expected %d %s objects, got %d", count, typ, len(byType[typ]))
+		}
+	}
+
+	indicator := byType["indicator"][0]
+	if indicator.Pattern != cfg.Query || indicator.PatternType != "sql" || indicator.ValidFrom != "2024-03-01T12:00:00.000Z" {
+		t.Errorf("unexpected indicator %+v", indicator)
+	}
+	if indicator.Confidence == nil || *indicator.Confidence != 85 {
+		t.Errorf("unexpected indicator confidence %v", indicator.Confidence)
+	}
+
+	pattern := byType["attack-pattern"][0]
+	if pattern.ExternalReferences[0].ExternalID != "T1078" || pattern.KillChainPhases[0].PhaseName != "initial-access" {
+		t.Errorf("unexpected attack pattern %+v", pattern)
+	}
+
+	// Observables use the IDs the specification derives from their properties.
+	stixNamespace := uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+	expectedID := "ipv4-addr--" + uuid.NewSHA1(stixNamespace, []byte(`{"value":"203.0.113.7"}`)).String()
+	if id := byType["ipv4-addr"][0].ID; id != expectedID {
+		t.Errorf("unexpected ipv4-addr id %s, want %s", id, expectedID)
+	}
+
+	// References resolve within the bundle.
+	sighting := byType["sighting"][0]
+	refs := append([]string{sighting.SightingOfRef}, sighting.ObservedDataRefs...)
+	refs = append(refs, byType["observed-data"][0].ObjectRefs...)
+	refs = append(refs, byType["relationship"][0].SourceRef, byType["relationship"][0].TargetRef)
+	for _, ref := range refs {
+		if !ids[ref] {
+			t.Errorf("reference %s does not resolve within the bundle", ref)
+		}
+	}
+
+	// The same finding maps to the same bundle.
+	again, err := signal.BuildSTIXBundle(ocsfRow, &cfg)
+	if err != nil {
+		t.Fatalf("BuildSTIXBundle() unexpected error: %v", err)
+	}
+	if again.ID != bundle.ID {
+		t.Errorf("expected stable bundle ids, got %s and %s", bundle.ID, again.ID)
+	}
+}
@@ -0,0 +1,222 @@
+package signal
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// Epoch timestamp formats accepted by the timestamp transform.
+const (
+	EpochSeconds = "epoch_seconds"
+	EpochMillis  = "epoch_millis"
+	EpochMicros  = "epoch_micros"
+	EpochNanos   = "epoch_nanos"
+)
+
+// redacted replaces redacted values.
+const redacted = "[REDACTED]"
+
+var hashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha1":   sha1.New,
+	"md5":    md5.New,
+}
+
+// regexps caches compiled transform expressions, since signals are built for every result.
+var regexps sync.Map
+
+// fieldValue returns the value of an output field: its source, or its sources concatenated, after
+// applying the transforms. The default is returned as is if a source is missing or null.
+func fieldValue(row result.Row, f config.OutputField) (any, error) {
+	value, missing := sourceValue(row, f)
+	if missing != "" {
+		if f.Default != nil {
+			return *f.Default, nil
+		}
+		return nil, fmt.Errorf("source field %s not found in query results", missing)
+	}
+
+	for i, t := range f.Transforms {
+		var err error
+		value, err = applyTransform(t, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: transform %d: %w", f.Field, i+1, err)
+		}
+	}
+	return value, nil
+}
+
+// sourceValue looks up the source of f, returning the name of the first missing source if any.
+// Null values count as missing when there is a default.
+func sourceValue(row result.Row, f config.OutputField) (any, string) {
+	lookup := func(source string) (any, bool) {
+		v, ok := row.Get(source)
+		if !ok || v == nil && f.Default != nil {
+			return nil, false
+		}
+		return v, true
+	}
+
+	if len(f.Sources) == 0 {
+		v, ok := lookup(f.Source)
+		if !ok {
+			return nil, f.Source
+		}
+		return v, ""
+	}
+
+	parts := make([]string, 0, len(f.Sources))
+	for _, source := range f.Sources {
+		v, ok := lookup(source)
+		if !ok {
+			return nil, source
+		}
+		parts = append(parts, result.FormatValue(v))
+	}
+	return strings.Join(parts, f.Separator), ""
+}
+
+func applyTransform(t config.Transform, value any) (any, error) {
+	switch {
+	case t.Timestamp != "":
+		ts, err := parseTimestamp(value, t.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		return ts.UTC().Format(time.RFC3339Nano), nil
+	case t.Lowercase:
+		return strings.ToLower(result.FormatValue(value)), nil
+	case t.Uppercase:
+		return strings.ToUpper(result.FormatValue(value)), nil
+	case t.Trim:
+		return strings.TrimSpace(result.FormatValue(value)), nil
+	case t.Regex != "":
+		re, err := compileRegex(t.Regex)
+		if err != nil {
+			return nil, err
+		}
+		match := re.FindStringSubmatch(result.FormatValue(value))
+		if match == nil {
+			return "", nil
+		}
+		if len(match) > 1 {
+			return match[1], nil
+		}
+		return match[0], nil
+	case t.Split != nil:
+		parts := strings.Split(result.FormatValue(value), t.Split.Separator)
+		if t.Split.Index == nil {
+			list := make([]any, len(parts))
+			for i, p := range parts {
+				list[i] = p
+			}
+			return list, nil
+		}
+		i := *t.Split.Index
+		if i < 0 {
+			i += len(parts)
+		}
+		if i < 0 || i >= len(parts) {
+			return "", nil
+		}
+		return parts[i], nil
+	case t.Hash != "":
+		newHash, ok := hashes[t.Hash]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hash %s", t.Hash)
+		}
+		h := newHash()
+		h.Write([]byte(result.FormatValue(value)))
+		return hex.EncodeToString(h.Sum(nil)), nil
+	case t.Redact != nil:
+		s := []rune(result.FormatValue(value))
+		if t.Redact.Keep <= 0 || t.Redact.Keep >= len(s) {
+			return redacted, nil
+		}
+		return redacted + string(s[len(s)-t.Redact.Keep:]), nil
+	default:
+		return nil, fmt.Errorf("transform has no operation")
+	}
+}
+
+// parseTimestamp parses an epoch number in the given unit or a string in a Go time layout.
+func parseTimestamp(value any, format string) (time.Time, error) {
+	units := map[string]time.Duration{
+		EpochSeconds: time.Second,
+		EpochMillis:  time.Millisecond,
+		EpochMicros:  time.Microsecond,
+		EpochNanos:   time.Nanosecond,
+	}
+	unit, epoch := units[format]
+	if !epoch {
+		parsed, err := time.Parse(format, result.FormatValue(value))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		return parsed, nil
+	}
+
+	s := strings.TrimSpace(result.FormatValue(value))
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		perSecond := int64(time.Second / unit)
+		return time.Unix(i/perSecond, i%perSecond*int64(unit)), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse %s timestamp: %w", format, err)
+	}
+	sec, frac := math.Modf(f * float64(unit) / float64(time.Second))
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+}
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Store(expr, re)
+	return re, nil
+}
+
+// validateTransforms checks that every transform has exactly one valid operation.
+func validateTransforms(f config.OutputField) error {
+	if f.Source != "" && len(f.Sources) > 0 {
+		return fmt.Errorf("field %s: source and sources are mutually exclusive", f.Field)
+	}
+	for i, t := range f.Transforms {
+		ops := 0
+		for _, set := range []bool{t.Timestamp != "", t.Lowercase, t.Uppercase, t.Trim, t.Regex != "", t.Split != nil, t.Hash != "", t.Redact != nil} {
+			if set {
+				ops++
+			}
+		}
+		if ops != 1 {
+			return fmt.Errorf("field %s: transform %d must set exactly one operation, got %d", f.Field, i+1, ops)
+		}
+		if t.Regex != "" {
+			if _, err := compileRegex(t.Regex); err != nil {
+				return fmt.Errorf("field %s: transform %d: %w", f.Field, i+1, err)
+			}
+		}
+		if _, ok := hashes[t.Hash]; t.Hash != "" && !ok {
+			return fmt.Errorf("field %s: transform %d: unsupported hash %s", f.Field, i+1, t.Hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package signal_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+)
+
+func intPtr(i int) *int       { return &i }
+func strPtr(s string) *string { return &s }
+
+func TestBuildSignalTransforms(t *testing.T) {
+	cfg := &config.RuleConfig{
+		Output: config.Output{
+			Fields: []config.OutputField{
+				{Field: "timestamp", Source: "ts", Transforms: []config.Transform{{Timestamp: signal.EpochMillis}}},
+				{Field: "actor.user.name", Source: "email", Transforms: []config.Transform{
+					{Lowercase: true},
+					{Regex: `^([^@]+)@`},
+				}},
+				{Field: "actor.user.uid", Source: "principal", Transforms: []config.Transform{
+					{Split: &config.Split{Separator: "/", Index: intPtr(-1)}},
+				}},
+				{Field: "message", Sources: []string{"process", "host"}, Separator: " on "},
+				{Field: "resource.name", Source: "missing", Default: strPtr("unknown")},
+				{Field: "resource.uid", Source: "token", Transforms: []config.Transform{{Hash: "sha256"}}},
+				{Field: "metadata.event_id", Source: "card", Transforms: []config.Transform{{Redact: &config.Redact{Keep: 4}}}},
+				{Field: "extensions.groups", Source: "groups", Transforms: []config.Transform{{Split: &config.Split{Separator: ","}}}},
+			},
+		},
+	}
+	row := result.Row{
+		"ts":        int64(1709294400123),
+		"email":     "Alice@Example.com",
+		"principal": "users/alice",
+		"process":   "curl",
+		"host":      "web-1",
+		"token":     "secret",
+		"card":      "4111111111111111",
+		"groups":    "admins,devs",
+	}
+
+	sig, err := signal.BuildSignal(row, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &signal.Signal{
+		Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 123000000, time.UTC),
+		TTPs:      []map[string]string{},
+		Actor:     signal.Actor{User: signal.User{Name: "alice", UID: "alice"}},
+		Message:   "curl on web-1",
+		Resource: signal.Resource{
+			Name: "unknown",
+			UID:  "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b",
+		},
+		Metadata:   signal.Metadata{EventID: "[REDACTED]1111"},
+		Extensions: map[string]any{"groups": []any{"admins", "devs"}},
+	}
+	if diff := cmp.Diff(expected, sig, ignoreFindingUID); diff != "" {
+		t.Fatalf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestTimestampTransform(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		format   string
+		expected time.Time
+	}{
+		{name: "epoch seconds", value: "1709294400", format: signal.EpochSeconds, expected: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{name: "fractional epoch seconds", value: 1709294400.5, format: signal.EpochSeconds, expected: time.Date(2024, 3, 1, 12, 0, 0, 500000000, time.UTC)},
+		{name: "epoch micros", value: int64(1709294400000001), format: signal.EpochMicros, expected: time.Date(2024, 3, 1, 12, 0, 0, 1000, time.UTC)},
+		{name: "custom layout", value: "2024-03-01 12:00:00", format: "2006-01-02 15:04:05", expected: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.RuleConfig{Output: config.Output{Fields: []config.OutputField{
+				{Field: "timestamp", Source: "ts", Transforms: []config.Transform{{Timestamp: tt.format}}},
+			}}}
+			sig, err := signal.BuildSignal(result.Row{"ts": tt.value}, cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sig.Timestamp.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, sig.Timestamp)
+			}
+		})
+	}
+}
+
+func TestBuildSignalTransformErrors(t *testing.T) {
+	cfg := &config.RuleConfig{Output: config.Output{Fields: []config.OutputField{
+		{Field: "timestamp", Source: "ts", Transforms: []config.Transform{{Timestamp: signal.EpochMillis}}},
+	}}}
+	_, err := signal.BuildSignal(result.Row{"ts": "yesterday"}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "failed to parse epoch_millis timestamp") {
+		t.Fatalf("expected a timestamp error, got %v", err)
+	}
+
+	cfg = &config.RuleConfig{Output: config.Output{Fields: []config.OutputField{
+		{Field: "message", Sources: []string{"a", "b"}},
+	}}}
+	_, err = signal.BuildSignal(result.Row{"a": "x", "c": "y"}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "source field b not found") {
+		t.Fatalf("expected a missing source error, got %v", err)
+	}
+}
+
+func TestValidateTransforms(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      config.OutputField
+		errMessage string
+	}{
+		{
+			name:       "no operation",
+			field:      config.OutputField{Field: "message", Source: "m", Transforms: []config.Transform{{}}},
+			errMessage: "must set exactly one operation, got 0",
+		},
+		{
+			name:       "several operations",
+			field:      config.OutputField{Field: "message", Source: "m", Transforms: []config.Transform{{Lowercase: true, Trim: true}}},
+			errMessage: "must set exactly one operation, got 2",
+		},
+		{
+			name:       "invalid regex",
+			field:      config.OutputField{Field: "message", Source: "m", Transforms: []config.Transform{{Regex: "("}}},
+			errMessage: "missing closing )",
+		},
+		{
+			name:       "unsupported hash",
+			field:      config.OutputField{Field: "message", Source: "m", Transforms: []config.Transform{{Hash: "crc32"}}},
+			errMessage: "unsupported hash crc32",
+		},
+		{
+			name:       "source and sources",
+			field:      config.OutputField{Field: "message", Source: "m", Sources: []string{"a"}},
+			errMessage: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := signal.ValidateFields([]config.OutputField{tt.field})
+			if err == nil || !strings.Contains(err.Error(), tt.errMessage) {
+				t.Fatalf("expected error containing %q, got %v", tt.errMessage, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package state persists data across rule runs.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Store loads and saves JSON-encoded values by key.
+type Store interface {
+	// Load decodes the value stored under key into v and reports whether it exists.
+	Load(ctx context.Context, key string, v any) (bool, error)
+	// Save stores v under key, replacing any previous value.
+	Save(ctx context.Context, key string, v any) error
+}
+
+// unsafeKeyChars are replaced in file names, so keys can't escape the state directory.
+var unsafeKeyChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// FileStore keeps each key in a JSON file within a directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a store writing to dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("state path is not set")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Load(ctx context.Context, key string, v any) (bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode state %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Save writes to a temporary file first, so an interrupted run never leaves a partial file behind.
+func (s *FileStore) Save(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, unsafeKeyChars.ReplaceAllString(key, "_")+".json")
+}
@@ -0,0 +1,53 @@
+package state_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nianticlabs/venator/internal/state"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err := state.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() unexpected error: %v", err)
+	}
+
+	var got map[string]int
+	found, err := store.Load(ctx, "missing", &got)
+	if err != nil || found {
+		t.Fatalf("expected a missing key, got found=%t err=%v", found, err)
+	}
+
+	want := map[string]int{"count": 3}
+	if err := store.Save(ctx, "rule/../uid", want); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	found, err = store.Load(ctx, "rule/../uid", &got)
+	if err != nil || !found {
+		t.Fatalf("expected the saved key, got found=%t err=%v", found, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected value (-want +got):\n%s", diff)
+	}
+
+	// Keys are sanitized, so nothing is written outside the directory.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to list state directory: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "rule_.._uid.json" {
+		t.Errorf("unexpected state files %v", entries)
+	}
+}
+
+func TestNewFileStoreRequiresPath(t *testing.T) {
+	if _, err := state.NewFileStore(""); err == nil {
+		t.Fatalf("expected an error for an empty path")
+	}
+}
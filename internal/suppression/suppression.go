@@ -0,0 +1,156 @@
+// Package suppression drops findings that were already published by a previous run.
+package suppression
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/signal"
+	"github.com/nianticlabs/venator/internal/state"
+)
+
+var logger = logrus.StandardLogger().WithField("pkg", "internal/suppression")
+
+// Fields added to rolled-up findings.
+const (
+	SuppressedCountField     = "suppressed_count"
+	SuppressedFirstSeenField = "suppressed_first_seen"
+	SuppressedLastSeenField  = "suppressed_last_seen"
+)
+
+// Entry is the suppression window of a fingerprint.
+type Entry struct {
+	Until     time.Time `json:"until"`
+	Count     int64     `json:"count,omitempty"`
+	FirstSeen time.Time `json:"first_seen,omitempty"`
+	LastSeen  time.Time `json:"last_seen,omitempty"`
+	// Sample is the last suppressed row, kept as JSON so its values decode to row types.
+	Sample json.RawMessage `json:"sample,omitempty"`
+}
+
+// Suppressor filters findings against the suppression windows kept in a state store. Filter
+// computes the new windows and Commit persists them once the findings were published, so findings
+// of a failed run are published again.
+type Suppressor struct {
+	store   state.Store
+	now     func() time.Time
+	pending map[string]*Entry
+}
+
+// New returns a suppressor keeping its state in store.
+func New(store state.Store) *Suppressor {
+	return &Suppressor{store: store, now: time.Now}
+}
+
+// Filter returns the rows to publish: rows whose fingerprint has no active window, and with rollup
+// enabled, the last suppressed row of every window that ended with its suppressed count.
+func (s *Suppressor) Filter(ctx context.Context, rows []result.Row, cfg *config.RuleConfig) ([]result.Row, error) {
+	if cfg.Suppression == nil || cfg.Suppression.SuppressFor <= 0 {
+		return rows, nil
+	}
+
+	entries := make(map[string]*Entry)
+	if _, err := s.store.Load(ctx, stateKey(cfg), &entries); err != nil {
+		return nil, fmt.Errorf("failed to load suppression state: %w", err)
+	}
+	now := s.now().UTC()
+
+	// Ended windows are visited in a fixed order, so rollups are published in the same order on retries.
+	fps := make([]string, 0, len(entries))
+	for fp := range entries {
+		fps = append(fps, fp)
+	}
+	sort.Strings(fps)
+
+	var out []result.Row
+	for _, fp := range fps {
+		entry := entries[fp]
+		if now.Before(entry.Until) {
+			continue
+		}
+		if cfg.Suppression.Rollup && entry.Count > 0 {
+			row, ok, err := rollup(entry)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, row)
+			} else {
+				logger.Warnf("rule %s: no sample of the %d finding(s) suppressed for %s, skipping rollup", cfg.Name, entry.Count, fp)
+			}
+		}
+		delete(entries, fp)
+	}
+
+	suppressed := 0
+	for _, row := range rows {
+		fp, err := signal.FindingUID(row, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if entry, ok := entries[fp]; ok {
+			if entry.Count == 0 {
+				entry.FirstSeen = now
+			}
+			entry.Count++
+			entry.LastSeen = now
+			if cfg.Suppression.Rollup {
+				if entry.Sample, err = json.Marshal(row); err != nil {
+					return nil, err
+				}
+			}
+			suppressed++
+			continue
+		}
+		entries[fp] = &Entry{Until: now.Add(cfg.Suppression.SuppressFor)}
+		out = append(out, row)
+	}
+
+	if suppressed > 0 {
+		logger.Infof("rule %s: suppressed %d finding(s) published within the last %s", cfg.Name, suppressed, cfg.Suppression.SuppressFor)
+	}
+	s.pending = entries
+	return out, nil
+}
+
+// Commit persists the windows computed by the last Filter call.
+func (s *Suppressor) Commit(ctx context.Context, cfg *config.RuleConfig) error {
+	if s.pending == nil {
+		return nil
+	}
+	if err := s.store.Save(ctx, stateKey(cfg), s.pending); err != nil {
+		return fmt.Errorf("failed to save suppression state: %w", err)
+	}
+	s.pending = nil
+	return nil
+}
+
+// rollup returns the last suppressed row with the number of suppressed findings. It reports false
+// without a sample, e.g. if rollup was enabled during the window: the suppression fields alone are
+// not a finding and would fail the output field mapping.
+func rollup(entry *Entry) (result.Row, bool, error) {
+	if len(entry.Sample) == 0 {
+		return nil, false, nil
+	}
+	row, err := result.FromJSON(entry.Sample)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode suppressed finding: %w", err)
+	}
+	// The rollup is a finding of its own, so it doesn't reuse the UID of the suppressed finding.
+	delete(row, signal.FindingUIDField)
+	row[SuppressedCountField] = entry.Count
+	row[SuppressedFirstSeenField] = entry.FirstSeen.Format(time.RFC3339)
+	row[SuppressedLastSeenField] = entry.LastSeen.Format(time.RFC3339)
+	return row, true, nil
+}
+
+func stateKey(cfg *config.RuleConfig) string {
+	return "suppression-" + cfg.UID
+}
@@ -0,0 +1,148 @@
+package suppression
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/result"
+)
+
+// memoryStore keeps state JSON-encoded in memory, like a file store would on disk.
+type memoryStore struct {
+	data map[string][]byte
+}
+
+func (m *memoryStore) Load(ctx context.Context, key string, v any) (bool, error) {
+	data, ok := m.data[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (m *memoryStore) Save(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	m.data[key] = data
+	return nil
+}
+
+func TestFilter(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.RuleConfig{
+		Name:        "test-rule",
+		UID:         "test-uid",
+		Fingerprint: &config.Fingerprint{Fields: []string{"user"}},
+		Suppression: &config.Suppression{SuppressFor: time.Hour, Rollup: true},
+	}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := New(&memoryStore{data: make(map[string][]byte)})
+	s.now = func() time.Time { return now }
+
+	run := func(rows ...result.Row) []result.Row {
+		t.Helper()
+		out, err := s.Filter(ctx, rows, cfg)
+		if err != nil {
+			t.Fatalf("Filter() unexpected error: %v", err)
+		}
+		if err := s.Commit(ctx, cfg); err != nil {
+			t.Fatalf("Commit() unexpected error: %v", err)
+		}
+		return out
+	}
+
+	// The first finding is published and duplicates within the run are suppressed.
+	got := run(result.Row{"user": "alice", "n": int64(1)}, result.Row{"user": "alice", "n": int64(2)})
+	if diff := cmp.Diff([]result.Row{{"user": "alice", "n": int64(1)}}, got); diff != "" {
+		t.Errorf("unexpected first run (-want +got):\n%s", diff)
+	}
+
+	// Within the window only new fingerprints are published.
+	now = now.Add(30 * time.Minute)
+	got = run(result.Row{"user": "alice", "n": int64(3)}, result.Row{"user": "bob", "n": int64(1)})
+	if diff := cmp.Diff([]result.Row{{"user": "bob", "n": int64(1)}}, got); diff != "" {
+		t.Errorf("unexpected second run (-want +got):\n%s", diff)
+	}
+
+	// Once the window ended, the rollup of alice's suppressed findings is published along with the
+	// new finding, which starts a new window. Bob's window ended without suppressed findings.
+	now = now.Add(45 * time.Minute)
+	got = run(result.Row{"user": "alice", "n": int64(4)})
+	expected := []result.Row{
+		{
+			"user":                   "alice",
+			"n":                      int64(3),
+			SuppressedCountField:     int64(2),
+			SuppressedFirstSeenField: "2024-03-01T12:00:00Z",
+			SuppressedLastSeenField:  "2024-03-01T12:30:00Z",
+		},
+		{"user": "alice", "n": int64(4)},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected third run (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilterRollupWithoutSample(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.RuleConfig{UID: "test-uid", Suppression: &config.Suppression{SuppressFor: time.Hour}}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := New(&memoryStore{data: make(map[string][]byte)})
+	s.now = func() time.Time { return now }
+
+	// Findings suppressed before rollup was enabled have no sample to roll up.
+	rows := []result.Row{{"user": "alice"}, {"user": "alice"}}
+	if _, err := s.Filter(ctx, rows, cfg); err != nil {
+		t.Fatalf("Filter() unexpected error: %v", err)
+	}
+	if err := s.Commit(ctx, cfg); err != nil {
+		t.Fatalf("Commit() unexpected error: %v", err)
+	}
+
+	cfg.Suppression.Rollup = true
+	now = now.Add(2 * time.Hour)
+	got, err := s.Filter(ctx, []result.Row{{"user": "bob"}}, cfg)
+	if err != nil {
+		t.Fatalf("Filter() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]result.Row{{"user": "bob"}}, got); diff != "" {
+		t.Errorf("expected no rollup without a sample (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilterWithoutCommit(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.RuleConfig{UID: "test-uid", Suppression: &config.Suppression{SuppressFor: time.Hour}}
+	s := New(&memoryStore{data: make(map[string][]byte)})
+	rows := []result.Row{{"user": "alice"}}
+
+	// Findings of a run whose publishing failed are published again.
+	for i := 0; i < 2; i++ {
+		got, err := s.Filter(ctx, rows, cfg)
+		if err != nil {
+			t.Fatalf("Filter() unexpected error: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("run %d: expected the finding to be published, got %v", i, got)
+		}
+	}
+}
+
+func TestFilterDisabled(t *testing.T) {
+	s := New(&memoryStore{data: make(map[string][]byte)})
+	rows := []result.Row{{"user": "alice"}, {"user": "alice"}}
+	got, err := s.Filter(context.Background(), rows, &config.RuleConfig{UID: "test-uid"})
+	if err != nil {
+		t.Fatalf("Filter() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(rows, got); diff != "" {
+		t.Errorf("unexpected rows (-want +got):\n%s", diff)
+	}
+}
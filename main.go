@@ -7,11 +7,21 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/nianticlabs/venator/connector"
+	"github.com/nianticlabs/venator/internal/aggregation"
 	"github.com/nianticlabs/venator/internal/config"
+	"github.com/nianticlabs/venator/internal/correlation"
+	"github.com/nianticlabs/venator/internal/enrichment"
 	"github.com/nianticlabs/venator/internal/exclusion"
+	"github.com/nianticlabs/venator/internal/geoip"
+	"github.com/nianticlabs/venator/internal/intel"
 	"github.com/nianticlabs/venator/internal/llm"
 	llmconfig "github.com/nianticlabs/venator/internal/llm/config"
 	"github.com/nianticlabs/venator/internal/llm/model"
+	"github.com/nianticlabs/venator/internal/result"
+	"github.com/nianticlabs/venator/internal/scoring"
+	"github.com/nianticlabs/venator/internal/signal"
+	"github.com/nianticlabs/venator/internal/state"
+	"github.com/nianticlabs/venator/internal/suppression"
 )
 
 var logger = logrus.StandardLogger()
@@ -32,6 +42,24 @@ func main() {
 		logger.Fatalf("error reading rule config: %s", err)
 	}
 
+	if ruleCfg.Output.MapsFields() {
+		if err := signal.ValidateFields(ruleCfg.Output.Fields); err != nil {
+			logger.Fatalf("error validating rule output: %s", err)
+		}
+	}
+
+	if ruleCfg.Aggregation != nil {
+		if err := aggregation.Validate(ruleCfg.Aggregation); err != nil {
+			logger.Fatalf("error validating rule aggregation: %s", err)
+		}
+	}
+
+	if ruleCfg.Correlation != nil {
+		if err := correlation.Validate(ruleCfg.Correlation); err != nil {
+			logger.Fatalf("error validating rule correlation: %s", err)
+		}
+	}
+
 	globalCfg, err := config.ParseGlobalConfig(args.GlobalConfigPath)
 	if err != nil {
 		logger.Fatalf("error reading global config: %s", err)
@@ -39,9 +67,22 @@ func main() {
 
 	connectorRegistry := connector.NewRegistry(ctx, globalCfg)
 
-	qr, err := connectorRegistry.GetQueryRunner(ruleCfg.QueryEngine)
-	if err != nil {
-		logger.Fatalf("error retrieving query runner '%s': %s", ruleCfg.QueryEngine, err)
+	// Correlation rules run the queries of their stages instead of their own query.
+	queryCfgs := []*config.RuleConfig{ruleCfg}
+	if ruleCfg.Correlation != nil {
+		queryCfgs = nil
+		for _, stage := range ruleCfg.Correlation.Stages {
+			queryCfgs = append(queryCfgs, correlation.StageRule(ruleCfg, stage))
+		}
+	}
+
+	var queryRunners []connector.QueryRunner
+	for _, queryCfg := range queryCfgs {
+		qr, err := connectorRegistry.GetQueryRunner(queryCfg.QueryEngine)
+		if err != nil {
+			logger.Fatalf("error retrieving query runner '%s': %s", queryCfg.QueryEngine, err)
+		}
+		queryRunners = append(queryRunners, qr)
 	}
 
 	var publishers []connector.Publisher
@@ -62,6 +103,57 @@ func main() {
 		logger.Infof("Loaded exclusions from %s", ruleCfg.ExclusionsPath)
 	}
 
+	var geoEnricher *geoip.Enricher
+	if ruleCfg.GeoIP != nil {
+		geoEnricher, err = geoip.New(globalCfg.GeoIP, ruleCfg)
+		if err != nil {
+			logger.Fatalf("error initializing GeoIP enrichment: %s", err)
+		}
+		defer geoEnricher.Close()
+		logger.Infof("GeoIP enrichment enabled for %v", geoEnricher.Fields())
+	}
+
+	var enricher *enrichment.Enricher
+	if len(ruleCfg.Enrichments) > 0 {
+		query := func(ctx context.Context, cfg *config.RuleConfig) ([]result.Row, error) {
+			qr, err := connectorRegistry.GetQueryRunner(cfg.QueryEngine)
+			if err != nil {
+				return nil, err
+			}
+			return qr.Query(ctx, cfg)
+		}
+		enricher, err = enrichment.New(ctx, ruleCfg, query)
+		if err != nil {
+			logger.Fatalf("error initializing enrichments: %s", err)
+		}
+		logger.Infof("Loaded %d lookup tables", len(ruleCfg.Enrichments))
+	}
+
+	var matcher *intel.Matcher
+	if ruleCfg.Intel != nil {
+		matcher, err = intel.New(globalCfg.Intel, ruleCfg)
+		if err != nil {
+			logger.Fatalf("error initializing intel matching: %s", err)
+		}
+	}
+
+	var scorer *scoring.Scorer
+	if ruleCfg.Scoring != nil {
+		scorer, err = scoring.New(ruleCfg)
+		if err != nil {
+			logger.Fatalf("error initializing scoring: %s", err)
+		}
+	}
+
+	var suppressor *suppression.Suppressor
+	if ruleCfg.Suppression != nil {
+		store, err := state.NewFileStore(globalCfg.State.Path)
+		if err != nil {
+			logger.Fatalf("error initializing state store: %s", err)
+		}
+		suppressor = suppression.New(store)
+	}
+
 	var llmClient model.Client
 	if ruleCfg.LLM != nil && ruleCfg.LLM.Enabled {
 		llmConfig := llmconfig.Config{
@@ -78,24 +170,69 @@ func main() {
 		}
 	}
 
-	parsedResponse, err := qr.Query(ctx, ruleCfg)
-	if err != nil {
-		logger.Fatalf("error running the query: %s", err)
+	// Query runners reading from a queue only get acknowledged once all results were handled.
+	handled := false
+	queryResults := make([][]result.Row, len(queryRunners))
+	for i, qr := range queryRunners {
+		queryResults[i], err = qr.Query(ctx, queryCfgs[i])
+		if err != nil {
+			if ruleCfg.Correlation == nil {
+				logger.Fatalf("error running the query: %s", err)
+			}
+			// Results of previous stages are released by the deferred settle calls.
+			logger.Errorf("error running the query of stage '%s': %s", ruleCfg.Correlation.Stages[i].Name, err)
+			return
+		}
+		if acker, ok := qr.(connector.Acknowledger); ok {
+			defer settle(ctx, acker, &handled)
+		}
 	}
 
-	if excluder != nil {
-		var filtered []map[string]string
-		for _, result := range parsedResponse {
-			if excluder.IsExcluded(result) {
-				logger.Debugf("Excluded result: %+v", result)
-				continue
-			}
-			filtered = append(filtered, result)
+	parsedResponse := queryResults[0]
+	if ruleCfg.Correlation != nil {
+		parsedResponse, err = correlation.Correlate(queryResults, ruleCfg.Correlation)
+		if err != nil {
+			logger.Errorf("error correlating query results: %s", err)
+			return
+		}
+		logger.Infof("Correlation matched %d results", len(parsedResponse))
+	}
+
+	if ruleCfg.Aggregation != nil {
+		parsedResponse, err = aggregation.Aggregate(parsedResponse, ruleCfg.Aggregation)
+		if err != nil {
+			logger.Errorf("error aggregating query results: %s", err)
+			return
 		}
-		parsedResponse = filtered
+		logger.Infof("After aggregation, %d results remain", len(parsedResponse))
+	}
+
+	// Finding UIDs are derived from the query results, before enrichment and scoring add fields.
+	if err := signal.SetFindingUIDs(parsedResponse, ruleCfg); err != nil {
+		logger.Warnf("error computing finding UIDs: %s", err)
+	}
+
+	if geoEnricher != nil {
+		geoEnricher.Enrich(parsedResponse)
+	}
+
+	if enricher != nil {
+		enricher.Enrich(parsedResponse)
+	}
+
+	if matcher != nil {
+		parsedResponse = matcher.Match(parsedResponse)
+	}
+
+	if excluder != nil {
+		parsedResponse = excluder.Filter(parsedResponse)
 		logger.Infof("After exclusions, %d results remain", len(parsedResponse))
 	}
 
+	if scorer != nil {
+		parsedResponse = scorer.Score(parsedResponse)
+	}
+
 	if ruleCfg.LLM != nil && ruleCfg.LLM.Enabled {
 		parsedResponse, err = llm.Process(ctx, llmClient, parsedResponse, ruleCfg)
 		if err != nil {
@@ -104,25 +241,64 @@ func main() {
 		}
 		if len(parsedResponse) == 0 {
 			logger.Infof("No results from LLM to publish")
+			handled = true
 			return
 		}
 		logger.Infof("LLM processing completed successfully")
 	}
 
+	if suppressor != nil {
+		parsedResponse, err = suppressor.Filter(ctx, parsedResponse, ruleCfg)
+		if err != nil {
+			logger.Errorf("error applying suppression: %s", err)
+			return
+		}
+		// Suppression runs on the findings left after the LLM, so windows only start for findings that are
+		// published. They are persisted once the results were handled, so a failed run is retried.
+		defer commitSuppression(ctx, suppressor, ruleCfg, &handled)
+		logger.Infof("After suppression, %d results remain", len(parsedResponse))
+	}
+
 	if len(parsedResponse) == 0 {
 		logger.Infof("No results to publish")
+		handled = true
 		return
 	}
 
+	handled = true
 	for i, pub := range publishers {
 		if err := pub.Publish(ctx, parsedResponse, ruleCfg); err != nil {
 			logger.Errorf("error publishing to '%s': %s", ruleCfg.Publishers[i], err)
+			handled = false
 		} else {
 			logger.Infof("Successfully published to '%s'", ruleCfg.Publishers[i])
 		}
 	}
 }
 
+// settle acknowledges the query results if they were handled, or releases them for redelivery otherwise.
+func settle(ctx context.Context, acker connector.Acknowledger, handled *bool) {
+	if *handled {
+		if err := acker.Ack(ctx); err != nil {
+			logger.Errorf("error acknowledging query results: %s", err)
+		}
+		return
+	}
+	if err := acker.Nack(ctx); err != nil {
+		logger.Errorf("error releasing query results: %s", err)
+	}
+}
+
+// commitSuppression persists the suppression windows if the results were handled.
+func commitSuppression(ctx context.Context, suppressor *suppression.Suppressor, ruleCfg *config.RuleConfig, handled *bool) {
+	if !*handled {
+		return
+	}
+	if err := suppressor.Commit(ctx, ruleCfg); err != nil {
+		logger.Errorf("error saving suppression state: %s", err)
+	}
+}
+
 func setLogLevel(level string) {
 	lvl, err := logrus.ParseLevel(level)
 	if err != nil {